@@ -1,38 +1,110 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"time"
 
 	handlers "github.com/gomesmatheus/tc-pedido/delivery/http/handler"
+	"github.com/gomesmatheus/tc-pedido/infraestructure/config"
 	"github.com/gomesmatheus/tc-pedido/infraestructure/database"
+	"github.com/gomesmatheus/tc-pedido/infraestructure/eventos"
+	"github.com/gomesmatheus/tc-pedido/infraestructure/gateway"
+	categoria_usecase "github.com/gomesmatheus/tc-pedido/usecase/categoria"
+	idempotencia_usecase "github.com/gomesmatheus/tc-pedido/usecase/idempotencia"
 	pedido_usecase "github.com/gomesmatheus/tc-pedido/usecase/pedido"
 	produto_usecase "github.com/gomesmatheus/tc-pedido/usecase/produto"
 )
 
+const (
+	intervaloLimpezaIdempotencia = time.Hour
+	intervaloReenvioReembolsos   = time.Minute
+)
+
 func main() {
-	pedidoRepository, err := database.NewPedidoRepository()
-	produtoRepository, err := database.NewProdutoRepository()
+	args := os.Args[1:]
+	migrate := len(args) > 0 && args[0] == "migrate"
+	if migrate {
+		args = args[1:]
+	}
+
+	cfg, args, err := config.Carregar(args)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	configurarLog(cfg.LogLevel)
+
+	if migrate {
+		if err := executarMigrate(cfg, args); err != nil {
+			log.Fatalf("Error running migrations: %v", err)
+		}
+		return
+	}
 
-	// pedidoRepository, err := database.NewPedidoRepositoryLocal()
-	// produtoRepository, err := database.NewProdutoRepositoryLocal()
+	if err := run(cfg); err != nil {
+		log.Fatal(err)
+	}
+}
 
+func run(cfg config.Config) error {
+	repositorios, err := database.NewRepositorios(cfg)
 	if err != nil {
-		log.Fatalf("Error initializing database: %v", err)
+		return fmt.Errorf("Error initializing database: %w", err)
 	}
 
-	produtoUseCases := produto_usecase.NewProdutoUseCases(produtoRepository)
+	produtoUseCases := produto_usecase.NewProdutoUseCases(repositorios.Produto, repositorios.Categoria)
 	produtoHandler := handlers.NewProdutoHandler(produtoUseCases)
 
-	pedidoUseCases := pedido_usecase.NewPedidoUseCases(pedidoRepository)
-	pedidoHandler := handlers.NewPedidoHandler(pedidoUseCases)
+	categoriaUseCases := categoria_usecase.NewCategoriaUseCases(repositorios.Categoria)
+	categoriaHandler := handlers.NewCategoriaHandler(categoriaUseCases)
 
-	http.HandleFunc("/produto", produtoHandler.CriacaoProdutoRoute)
-	http.HandleFunc("/produto/", produtoHandler.RecuperarProdutosRoute)
-	http.HandleFunc("/pedido", pedidoHandler.CriacaoPedidoRoute)
-	http.HandleFunc("/pedido/atualizar/", pedidoHandler.AtualizarPedidoRoute)
+	clienteGateway := gateway.NewClienteHttpGateway(cfg.ClienteUrl, cfg.ClienteTimeout, cfg.ClienteTentativas, cfg.ClienteBackoff)
+
+	pagamentoGateway := gateway.NewPagamentoHttpGateway(cfg.PagamentoUrl, cfg.PagamentoTimeout)
+
+	broadcaster := eventos.NewBroadcaster(eventos.CapacidadeRecentes)
+
+	pedidoUseCases := pedido_usecase.NewPedidoUseCases(repositorios.Pedido, repositorios.Produto, clienteGateway, pagamentoGateway, broadcaster)
+	executarPeriodicamente("reenviar_reembolsos", intervaloReenvioReembolsos, func(ctx context.Context) error {
+		solicitados, err := pedidoUseCases.ReenviarReembolsos(ctx)
+		if solicitados > 0 {
+			slog.Info("Pending refunds requested", "solicitados", solicitados)
+		}
+		return err
+	})
+	idempotenciaUseCases := idempotencia_usecase.NewIdempotenciaUseCases(repositorios.Idempotencia)
+	executarPeriodicamente("expirar_idempotencia", intervaloLimpezaIdempotencia, func(ctx context.Context) error {
+		removidas, err := idempotenciaUseCases.Expirar(ctx)
+		if removidas > 0 {
+			slog.Info("Expired idempotency keys", "removidas", removidas)
+		}
+		return err
+	})
+	if cfg.PagamentoSegredo == "" {
+		slog.Warn("PAGAMENTO_WEBHOOK_SECRET not set, payment notifications will be rejected")
+	}
+	pedidoHandler := handlers.NewPedidoHandler(pedidoUseCases, idempotenciaUseCases, cfg.PagamentoSegredo)
+	eventosPedidoHandler := handlers.NewEventosPedidoHandler(pedidoUseCases, broadcaster)
+
+	roteador := handlers.NovoRoteadorComPrazo(cfg.HttpPrazo, produtoHandler, categoriaHandler, pedidoHandler, eventosPedidoHandler, handlers.NewDocsHandler())
+
+	server := &http.Server{
+		Addr:         cfg.HttpAddr,
+		Handler:      handlers.ComRequestId(roteador),
+		ReadTimeout:  cfg.HttpReadTimeout,
+		WriteTimeout: cfg.HttpWriteTimeout,
+	}
+
+	slog.Info("Pedido ms running!", "addr", cfg.HttpAddr, "db_driver", cfg.DbDriver)
+	return server.ListenAndServe()
+}
 
-	fmt.Println("Pedido ms running!")
-	log.Fatal(http.ListenAndServe(":3333", nil))
+func configurarLog(nivel string) {
+	var level slog.Level
+	level.UnmarshalText([]byte(nivel))
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level})))
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gomesmatheus/tc-pedido/infraestructure/config"
+	"github.com/gomesmatheus/tc-pedido/infraestructure/database"
+)
+
+const usoMigrate = "uso: app migrate [flags] [up | status | down [n]]"
+
+func executarMigrate(cfg config.Config, args []string) error {
+	comando := "up"
+	if len(args) > 0 {
+		comando = args[0]
+	}
+
+	migrador, err := database.NewMigrador(cfg.DbDriver, cfg.DbUrl)
+	if err != nil {
+		return err
+	}
+	defer migrador.Fechar()
+
+	switch comando {
+	case "up":
+		n, err := migrador.Aplicar()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d migração(ões) aplicada(s)\n", n)
+	case "status":
+		status, err := migrador.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			situacao := "pendente"
+			if s.Aplicada {
+				situacao = "aplicada"
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Versao, s.Nome, situacao)
+		}
+	case "down":
+		passos := 1
+		if len(args) > 1 {
+			passos, err = strconv.Atoi(args[1])
+			if err != nil || passos < 1 {
+				return fmt.Errorf("número de migrações inválido: %s\n%s", args[1], usoMigrate)
+			}
+		}
+		n, err := migrador.Reverter(passos)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d migração(ões) revertida(s)\n", n)
+	default:
+		return fmt.Errorf("comando desconhecido: %s\n%s", comando, usoMigrate)
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// executarPeriodicamente roda tarefa a cada intervalo enquanto o processo estiver de pé. Uma
+// falha só é registrada no log; a tarefa é executada de novo no próximo ciclo.
+func executarPeriodicamente(nome string, intervalo time.Duration, tarefa func(ctx context.Context) error) {
+	go func() {
+		ticker := time.NewTicker(intervalo)
+		defer ticker.Stop()
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), intervalo)
+			if err := tarefa(ctx); err != nil {
+				slog.Error("Periodic task failed", "tarefa", nome, "erro", err)
+			}
+			cancel()
+		}
+	}()
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+	"github.com/gomesmatheus/tc-pedido/usecase"
+)
+
+type CategoriaHandler struct {
+	categoriaUseCases usecase.CategoriaUseCases
+}
+
+func NewCategoriaHandler(categoriaUseCases usecase.CategoriaUseCases) *CategoriaHandler {
+	return &CategoriaHandler{
+		categoriaUseCases: categoriaUseCases,
+	}
+}
+
+func (c *CategoriaHandler) Registrar(mux Mux) {
+	mux.HandleFunc("POST /categoria", c.CriacaoCategoriaRoute)
+	mux.HandleFunc("GET /categoria", c.CriacaoCategoriaRoute)
+	mux.HandleFunc("GET /categoria/{id}", c.CategoriaPorIdRoute)
+	mux.HandleFunc("PUT /categoria/{id}", c.CategoriaPorIdRoute)
+	mux.HandleFunc("DELETE /categoria/{id}", c.CategoriaPorIdRoute)
+}
+
+func (c *CategoriaHandler) CriacaoCategoriaRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "POST" {
+		body, err := io.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
+			return
+		}
+
+		var categoria entity.Categoria
+		err = json.Unmarshal(body, &categoria)
+		if err != nil {
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
+			return
+		}
+
+		categoria, err = c.categoriaUseCases.CriarCategoria(r.Context(), categoria)
+		if err != nil {
+			escreverErro(w, r, err)
+			return
+		}
+
+		response, _ := json.Marshal(categoria)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(201)
+		w.Write(response)
+	} else if r.Method == "GET" {
+		categorias, err := c.categoriaUseCases.RecuperarCategorias(r.Context())
+		if err != nil {
+			escreverErro(w, r, err)
+			return
+		}
+		response, _ := json.Marshal(categorias)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		w.Write(response)
+	}
+}
+
+func (c *CategoriaHandler) CategoriaPorIdRoute(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		requisicaoInvalida(w, r, "Id de categoria inválido")
+		return
+	}
+
+	if r.Method == "GET" {
+		categoria, err := c.categoriaUseCases.RecuperarCategoria(r.Context(), int(id))
+		if err != nil {
+			escreverErro(w, r, err)
+			return
+		}
+		response, _ := json.Marshal(categoria)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		w.Write(response)
+	} else if r.Method == "PUT" {
+		body, err := io.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
+			return
+		}
+
+		var categoria entity.Categoria
+		err = json.Unmarshal(body, &categoria)
+		if err != nil {
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
+			return
+		}
+
+		err = c.categoriaUseCases.AtualizarCategoria(r.Context(), int(id), categoria)
+		if err != nil {
+			escreverErro(w, r, err)
+			return
+		}
+	} else if r.Method == "DELETE" {
+		err := c.categoriaUseCases.DeletarCategoria(r.Context(), int(id))
+		if err != nil {
+			escreverErro(w, r, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+type MockCategoriaUseCases struct {
+	CriarCategoriaFn      func(c entity.Categoria) (entity.Categoria, error)
+	RecuperarCategoriasFn func() ([]entity.Categoria, error)
+	RecuperarCategoriaFn  func(id int) (entity.Categoria, error)
+	AtualizarCategoriaFn  func(id int, c entity.Categoria) error
+	DeletarCategoriaFn    func(id int) error
+}
+
+func (m *MockCategoriaUseCases) CriarCategoria(ctx context.Context, c entity.Categoria) (entity.Categoria, error) {
+	return m.CriarCategoriaFn(c)
+}
+
+func (m *MockCategoriaUseCases) RecuperarCategorias(ctx context.Context) ([]entity.Categoria, error) {
+	return m.RecuperarCategoriasFn()
+}
+
+func (m *MockCategoriaUseCases) RecuperarCategoria(ctx context.Context, id int) (entity.Categoria, error) {
+	return m.RecuperarCategoriaFn(id)
+}
+
+func (m *MockCategoriaUseCases) AtualizarCategoria(ctx context.Context, id int, c entity.Categoria) error {
+	return m.AtualizarCategoriaFn(id, c)
+}
+
+func (m *MockCategoriaUseCases) DeletarCategoria(ctx context.Context, id int) error {
+	return m.DeletarCategoriaFn(id)
+}
+
+func TestCriacaoCategoriaRoute(t *testing.T) {
+	mock := &MockCategoriaUseCases{
+		CriarCategoriaFn: func(c entity.Categoria) (entity.Categoria, error) {
+			if c.Descricao == "" {
+				return c, entity.ErrCategoriaInvalida
+			}
+			if c.Descricao == "Lanche" {
+				return c, entity.ErrCategoriaDuplicada
+			}
+			c.Id = 5
+			return c, nil
+		},
+		RecuperarCategoriasFn: func() ([]entity.Categoria, error) {
+			return []entity.Categoria{{Id: 1, Descricao: "Lanche"}}, nil
+		},
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Successful creation",
+			method:       "POST",
+			body:         `{"descricao":"Combo"}`,
+			expectedCode: http.StatusCreated,
+			expectedBody: `{"id":5,"descricao":"Combo"}`,
+		},
+		{
+			name:         "Invalid categoria",
+			method:       "POST",
+			body:         `{}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `{"status":422,"codigo":"categoria_invalida","mensagem":"Categoria inválida","request_id":""}`,
+		},
+		{
+			name:         "Duplicated categoria",
+			method:       "POST",
+			body:         `{"descricao":"Lanche"}`,
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"status":409,"codigo":"categoria_duplicada","mensagem":"Já existe uma categoria com esta descrição","request_id":""}`,
+		},
+		{
+			name:         "Successful listing",
+			method:       "GET",
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"id":1,"descricao":"Lanche"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewCategoriaHandler(mock)
+
+			req := httptest.NewRequest(test.method, "/categoria", bytes.NewBuffer([]byte(test.body)))
+			rr := httptest.NewRecorder()
+
+			handler.CriacaoCategoriaRoute(rr, req)
+
+			resp := rr.Result()
+			defer resp.Body.Close()
+
+			if resp.StatusCode != test.expectedCode {
+				t.Errorf("expected status %d, got %d", test.expectedCode, resp.StatusCode)
+			}
+
+			responseBody, _ := ioutil.ReadAll(resp.Body)
+			if string(responseBody) != test.expectedBody {
+				t.Errorf("expected body %q, got %q", test.expectedBody, responseBody)
+			}
+		})
+	}
+}
+
+func TestCategoriaPorIdRoute(t *testing.T) {
+	mock := &MockCategoriaUseCases{
+		RecuperarCategoriaFn: func(id int) (entity.Categoria, error) {
+			if id != 1 {
+				return entity.Categoria{}, entity.ErrCategoriaNaoEncontrada
+			}
+			return entity.Categoria{Id: 1, Descricao: "Lanche"}, nil
+		},
+		AtualizarCategoriaFn: func(id int, c entity.Categoria) error {
+			return errors.New("error")
+		},
+		DeletarCategoriaFn: func(id int) error {
+			return entity.ErrCategoriaEmUso
+		},
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Successful retrieval",
+			method:       "GET",
+			url:          "/categoria/1",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"id":1,"descricao":"Lanche"}`,
+		},
+		{
+			name:         "Unknown categoria",
+			method:       "GET",
+			url:          "/categoria/9",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"status":404,"codigo":"categoria_nao_encontrada","mensagem":"Categoria não encontrada","request_id":""}`,
+		},
+		{
+			name:         "Invalid id",
+			method:       "GET",
+			url:          "/categoria/abc",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Id de categoria inválido","request_id":""}`,
+		},
+		{
+			name:         "Error updating categoria",
+			method:       "PUT",
+			url:          "/categoria/1",
+			body:         `{"descricao":"Combo"}`,
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"status":500,"codigo":"erro_interno","mensagem":"Erro interno ao processar a requisição","request_id":""}`,
+		},
+		{
+			name:         "Deleting categoria in use",
+			method:       "DELETE",
+			url:          "/categoria/1",
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"status":409,"codigo":"categoria_em_uso","mensagem":"Categoria possui produtos cadastrados","request_id":""}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewCategoriaHandler(mock)
+
+			req := httptest.NewRequest(test.method, test.url, bytes.NewBuffer([]byte(test.body)))
+			rr := httptest.NewRecorder()
+
+			NovoRoteador(handler).ServeHTTP(rr, req)
+
+			resp := rr.Result()
+			defer resp.Body.Close()
+
+			if resp.StatusCode != test.expectedCode {
+				t.Errorf("expected status %d, got %d", test.expectedCode, resp.StatusCode)
+			}
+
+			responseBody, _ := ioutil.ReadAll(resp.Body)
+			if string(responseBody) != test.expectedBody {
+				t.Errorf("expected body %q, got %q", test.expectedBody, responseBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// especificacao é o documento OpenAPI da API. docs_test.go garante que ele acompanha as
+// rotas registradas pelos handlers e os campos JSON das entidades.
+//
+//go:embed openapi.json
+var especificacao []byte
+
+const paginaDocs = `<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+  <meta charset="utf-8">
+  <title>tc-pedido - API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+type DocsHandler struct{}
+
+func NewDocsHandler() *DocsHandler {
+	return &DocsHandler{}
+}
+
+func (c *DocsHandler) Registrar(mux Mux) {
+	mux.HandleFunc("GET /openapi.json", c.OpenApiRoute)
+	mux.HandleFunc("GET /docs", c.DocsRoute)
+}
+
+func (c *DocsHandler) OpenApiRoute(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(especificacao)
+}
+
+func (c *DocsHandler) DocsRoute(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(200)
+	w.Write([]byte(paginaDocs))
+}
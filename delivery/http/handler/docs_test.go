@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+type documentoSpec struct {
+	OpenApi    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+// muxGravador registra apenas os patterns, para comparar as rotas dos handlers com a especificação.
+type muxGravador struct {
+	patterns []string
+}
+
+func (m *muxGravador) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.patterns = append(m.patterns, pattern)
+}
+
+func lerEspecificacao(t *testing.T) documentoSpec {
+	t.Helper()
+	var doc documentoSpec
+	if err := json.Unmarshal(especificacao, &doc); err != nil {
+		t.Fatalf("openapi.json inválido: %v", err)
+	}
+	return doc
+}
+
+func TestDocsRoutes(t *testing.T) {
+	roteador := NovoRoteador(NewDocsHandler())
+
+	t.Run("GET /openapi.json", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		roteador.ServeHTTP(rr, httptest.NewRequest("GET", "/openapi.json", nil))
+
+		if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "application/json" {
+			t.Fatalf("unexpected response: %d %s", rr.Code, rr.Header().Get("Content-Type"))
+		}
+		var doc documentoSpec
+		if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil || !strings.HasPrefix(doc.OpenApi, "3.") {
+			t.Errorf("expected an OpenAPI 3 document, got %q (%v)", doc.OpenApi, err)
+		}
+	})
+
+	t.Run("GET /docs", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		roteador.ServeHTTP(rr, httptest.NewRequest("GET", "/docs", nil))
+
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `url: "/openapi.json"`) {
+			t.Errorf("unexpected response: %d %s", rr.Code, rr.Body.String())
+		}
+	})
+}
+
+func TestEspecificacaoRotas(t *testing.T) {
+	doc := lerEspecificacao(t)
+
+	mux := &muxGravador{}
+	for _, rotas := range []Rotas{NewProdutoHandler(nil), NewCategoriaHandler(nil), NewPedidoHandler(nil, nil, ""), NewEventosPedidoHandler(nil, nil), NewDocsHandler()} {
+		rotas.Registrar(mux)
+	}
+
+	registradas := map[string]bool{}
+	for _, pattern := range mux.patterns {
+		registradas[pattern] = true
+		metodo, caminho, _ := strings.Cut(pattern, " ")
+		if _, ok := doc.Paths[caminho][strings.ToLower(metodo)]; !ok {
+			t.Errorf("route %q is not documented in openapi.json", pattern)
+		}
+	}
+
+	for caminho, operacoes := range doc.Paths {
+		for metodo := range operacoes {
+			if metodo == "parameters" {
+				continue
+			}
+			pattern := strings.ToUpper(metodo) + " " + caminho
+			if !registradas[pattern] && !registradas[strings.ToUpper(metodo)+" "+caminhoDoAlias(caminho)] {
+				t.Errorf("operation %q in openapi.json has no registered route", pattern)
+			}
+		}
+	}
+}
+
+// caminhoDoAlias resolve um caminho documentado que é atendido por um alias legado.
+func caminhoDoAlias(caminho string) string {
+	for _, alias := range aliasesLegados {
+		if id, ok := strings.CutPrefix(caminho, alias.prefixo); ok {
+			return alias.reescrever(id)
+		}
+	}
+	return ""
+}
+
+func TestEspecificacaoEntidades(t *testing.T) {
+	doc := lerEspecificacao(t)
+
+	schemas := map[string]any{
+		"Produto":                 entity.Produto{},
+		"Categoria":               entity.Categoria{},
+		"Pedido":                  entity.Pedido{},
+		"ProdutoPedido":           entity.ProdutoPedido{},
+		"PaginaPedidos":           entity.PaginaPedidos{},
+		"ErroCampo":               entity.ErroCampo{},
+		"PatchPedido":             PatchPedido{},
+		"NotificacaoPagamento":    NotificacaoPagamento{},
+		"SolicitacaoCancelamento": SolicitacaoCancelamento{},
+		"Cancelamento":            entity.Cancelamento{},
+		"Reembolso":               entity.Reembolso{},
+		"HistoricoPedido":         entity.HistoricoPedido{},
+		"AlteracaoPedido":         entity.AlteracaoPedido{},
+		"Problema":                Problema{},
+	}
+
+	for nome, valor := range schemas {
+		t.Run(nome, func(t *testing.T) {
+			schema, ok := doc.Components.Schemas[nome]
+			if !ok {
+				t.Fatalf("schema %s missing from openapi.json", nome)
+			}
+
+			var documentados []string
+			for campo := range schema.Properties {
+				documentados = append(documentados, campo)
+			}
+			sort.Strings(documentados)
+
+			campos := camposJson(reflect.TypeOf(valor))
+			if !reflect.DeepEqual(campos, documentados) {
+				t.Errorf("schema %s diverges from the Go type: type has %v, spec has %v", nome, campos, documentados)
+			}
+		})
+	}
+}
+
+func camposJson(tipo reflect.Type) []string {
+	var campos []string
+	for i := 0; i < tipo.NumField(); i++ {
+		campo := tipo.Field(i)
+		if !campo.IsExported() {
+			continue
+		}
+		nome, _, _ := strings.Cut(campo.Tag.Get("json"), ",")
+		if nome == "-" {
+			continue
+		}
+		if nome == "" {
+			nome = campo.Name
+		}
+		campos = append(campos, nome)
+	}
+	sort.Strings(campos)
+	return campos
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+// Problema é o corpo de todas as respostas de erro da API.
+type Problema struct {
+	Status    int                `json:"status"`
+	Codigo    string             `json:"codigo"`
+	Mensagem  string             `json:"mensagem"`
+	Campos    []entity.ErroCampo `json:"campos,omitempty"`
+	RequestId string             `json:"request_id"`
+}
+
+var statusPorTipo = map[entity.TipoErro]int{
+	entity.ErroNaoEncontrado: http.StatusNotFound,
+	entity.ErroValidacao:     http.StatusUnprocessableEntity,
+	entity.ErroConflito:      http.StatusConflict,
+	entity.ErroIndisponivel:  http.StatusServiceUnavailable,
+	entity.ErroNaoAutorizado: http.StatusUnauthorized,
+}
+
+// escreverErro traduz o erro retornado pelos casos de uso em um Problema. Erros que não
+// são de domínio são registrados no log e respondidos como erro interno, sem expor detalhes.
+func escreverErro(w http.ResponseWriter, r *http.Request, err error) {
+	var erroDominio *entity.ErroDominio
+	if errors.As(err, &erroDominio) {
+		if status, ok := statusPorTipo[erroDominio.Tipo]; ok {
+			escreverProblema(w, r, Problema{
+				Status:   status,
+				Codigo:   erroDominio.Codigo,
+				Mensagem: err.Error(),
+				Campos:   erroDominio.Campos,
+			})
+			return
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		escreverProblema(w, r, Problema{
+			Status:   http.StatusGatewayTimeout,
+			Codigo:   "prazo_esgotado",
+			Mensagem: "A requisição excedeu o tempo limite de processamento",
+		})
+		return
+	}
+
+	slog.Error("Erro ao processar requisição", "metodo", r.Method, "path", r.URL.Path, "request_id", RequestId(r.Context()), "erro", err)
+	escreverProblema(w, r, Problema{
+		Status:   http.StatusInternalServerError,
+		Codigo:   "erro_interno",
+		Mensagem: "Erro interno ao processar a requisição",
+	})
+}
+
+// requisicaoInvalida responde a requisições que não puderam ser interpretadas, como um
+// corpo que não é JSON válido ou um id fora do formato numérico.
+func requisicaoInvalida(w http.ResponseWriter, r *http.Request, mensagem string) {
+	escreverProblema(w, r, Problema{
+		Status:   http.StatusBadRequest,
+		Codigo:   "requisicao_invalida",
+		Mensagem: mensagem,
+	})
+}
+
+func escreverProblema(w http.ResponseWriter, r *http.Request, p Problema) {
+	p.RequestId = RequestId(r.Context())
+	response, _ := json.Marshal(p)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	w.Write(response)
+}
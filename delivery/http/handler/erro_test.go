@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+func TestEscreverErro(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Not found",
+			err:          entity.ErrPedidoNaoEncontrado,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"status":404,"codigo":"pedido_nao_encontrado","mensagem":"Pedido não encontrado","request_id":"abc"}`,
+		},
+		{
+			name:         "Validation with fields",
+			err:          entity.ErrClienteNaoCadastrado.ComCampos(entity.ErroCampo{Campo: "cpf", Mensagem: "CPF 1 não cadastrado"}),
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `{"status":422,"codigo":"cliente_nao_cadastrado","mensagem":"Cliente não cadastrado","campos":[{"campo":"cpf","mensagem":"CPF 1 não cadastrado"}],"request_id":"abc"}`,
+		},
+		{
+			name:         "Wrapped conflict",
+			err:          fmt.Errorf("%w: de %q para %q", entity.ErrTransicaoStatusInvalida, entity.StatusRecebido, entity.StatusFinalizado),
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"status":409,"codigo":"transicao_status_invalida","mensagem":"Transição de status não permitida: de \"Recebido\" para \"Finalizado\"","request_id":"abc"}`,
+		},
+		{
+			name:         "Dependency unavailable",
+			err:          entity.ErrClienteIndisponivel,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":503,"codigo":"cliente_indisponivel","mensagem":"Serviço de clientes indisponível","request_id":"abc"}`,
+		},
+		{
+			name:         "Deadline exceeded",
+			err:          fmt.Errorf("error querying pedidos: %w", context.DeadlineExceeded),
+			expectedCode: http.StatusGatewayTimeout,
+			expectedBody: `{"status":504,"codigo":"prazo_esgotado","mensagem":"A requisição excedeu o tempo limite de processamento","request_id":"abc"}`,
+		},
+		{
+			name:         "Internal error hides details",
+			err:          errors.New("pq: connection refused"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"status":500,"codigo":"erro_interno","mensagem":"Erro interno ao processar a requisição","request_id":"abc"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := ComRequestId(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				escreverErro(w, r, test.err)
+			}))
+
+			req := httptest.NewRequest("GET", "/pedido", nil)
+			req.Header.Set(HeaderRequestId, "abc")
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			resp := rr.Result()
+			defer resp.Body.Close()
+
+			if resp.StatusCode != test.expectedCode {
+				t.Errorf("expected status %d, got %d", test.expectedCode, resp.StatusCode)
+			}
+			if contentType := resp.Header.Get("Content-Type"); contentType != "application/problem+json" {
+				t.Errorf("expected problem content type, got %q", contentType)
+			}
+
+			responseBody, _ := ioutil.ReadAll(resp.Body)
+			if string(responseBody) != test.expectedBody {
+				t.Errorf("expected body %q, got %q", test.expectedBody, responseBody)
+			}
+		})
+	}
+}
+
+func TestComRequestId(t *testing.T) {
+	var recebido string
+	handler := ComRequestId(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recebido = RequestId(r.Context())
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/pedido", nil))
+
+	if len(recebido) != 32 {
+		t.Errorf("expected a generated request id, got %q", recebido)
+	}
+	if rr.Header().Get(HeaderRequestId) != recebido {
+		t.Errorf("expected response header %q, got %q", recebido, rr.Header().Get(HeaderRequestId))
+	}
+}
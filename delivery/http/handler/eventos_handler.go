@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+	"github.com/gomesmatheus/tc-pedido/usecase"
+)
+
+const (
+	HeaderLastEventId = "Last-Event-ID"
+
+	IntervaloHeartbeat = 15 * time.Second
+)
+
+// FonteEventosPedido entrega as alterações de um pedido, ou de todos quando pedidoId é zero,
+// a partir do evento seguinte a ultimoId.
+type FonteEventosPedido interface {
+	Assinar(pedidoId int, ultimoId string) (alteracoes <-chan entity.AlteracaoPedido, continuo bool, cancelar func())
+}
+
+// EventosPedidoHandler transmite as alterações de pedidos como Server-Sent Events. Cada
+// instância só conhece as alterações feitas por ela mesma.
+type EventosPedidoHandler struct {
+	pedidoUseCases usecase.PedidoUseCases
+	fonte          FonteEventosPedido
+	heartbeat      time.Duration
+}
+
+func NewEventosPedidoHandler(pedidoUseCases usecase.PedidoUseCases, fonte FonteEventosPedido) *EventosPedidoHandler {
+	return &EventosPedidoHandler{
+		pedidoUseCases: pedidoUseCases,
+		fonte:          fonte,
+		heartbeat:      IntervaloHeartbeat,
+	}
+}
+
+func (c *EventosPedidoHandler) Registrar(mux Mux) {
+	mux.HandleFunc("GET /pedido/eventos", c.EventosPedidosRoute)
+	mux.HandleFunc("GET /pedido/{id}/eventos", c.EventosPedidoRoute)
+}
+
+func (c *EventosPedidoHandler) EventosPedidosRoute(w http.ResponseWriter, r *http.Request) {
+	c.transmitir(w, r, 0)
+}
+
+func (c *EventosPedidoHandler) EventosPedidoRoute(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		requisicaoInvalida(w, r, "Id de pedido inválido")
+		return
+	}
+
+	if _, err := c.pedidoUseCases.RecuperarPedido(r.Context(), int(id)); err != nil {
+		escreverErro(w, r, err)
+		return
+	}
+
+	c.transmitir(w, r, int(id))
+}
+
+// transmitir mantém a conexão aberta até o cliente desconectar. Quando o Last-Event-ID
+// recebido não pode ser retomado, o evento reinicio avisa o cliente para recarregar os
+// pedidos. Se o cliente não acompanhar as alterações, a conexão é encerrada e ele retoma
+// pelo Last-Event-ID.
+func (c *EventosPedidoHandler) transmitir(w http.ResponseWriter, r *http.Request, pedidoId int) {
+	alteracoes, continuo, cancelar := c.fonte.Assinar(pedidoId, r.Header.Get(HeaderLastEventId))
+	defer cancelar()
+
+	controle := http.NewResponseController(w)
+	controle.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(200)
+	if !continuo {
+		fmt.Fprint(w, "event: reinicio\ndata: {}\n\n")
+	}
+	if err := controle.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(c.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case a, ok := <-alteracoes:
+			if !ok {
+				return
+			}
+			dados, _ := json.Marshal(a)
+			_, err = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", a.Id, dados)
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+		}
+		if err == nil {
+			err = controle.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
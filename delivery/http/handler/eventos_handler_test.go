@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+type mockFonteEventos struct {
+	alteracoes chan entity.AlteracaoPedido
+	continuo   bool
+	pedidoId   int
+	ultimoId   string
+	cancelada  bool
+}
+
+func (m *mockFonteEventos) Assinar(pedidoId int, ultimoId string) (<-chan entity.AlteracaoPedido, bool, func()) {
+	m.pedidoId, m.ultimoId = pedidoId, ultimoId
+	return m.alteracoes, m.continuo, func() { m.cancelada = true }
+}
+
+// novaFonte devolve uma fonte que entrega as alterações e fecha o canal, encerrando o fluxo.
+func novaFonte(continuo bool, alteracoes ...entity.AlteracaoPedido) *mockFonteEventos {
+	canal := make(chan entity.AlteracaoPedido, len(alteracoes))
+	for _, a := range alteracoes {
+		canal <- a
+	}
+	close(canal)
+	return &mockFonteEventos{alteracoes: canal, continuo: continuo}
+}
+
+func TestEventosPedidosRoute(t *testing.T) {
+	registrado := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Streams every alteracao", func(t *testing.T) {
+		fonte := novaFonte(true,
+			entity.AlteracaoPedido{Id: "g-4", PedidoId: 1, Evento: entity.EventoStatus, Status: entity.StatusPronto, PagamentoAprovado: true, Responsavel: "cozinha", RegistradoEm: registrado},
+			entity.AlteracaoPedido{Id: "g-5", PedidoId: 2, Evento: entity.EventoCriacao, Status: entity.StatusRecebido, Responsavel: entity.ResponsavelCliente, RegistradoEm: registrado},
+		)
+
+		req := httptest.NewRequest("GET", "/pedido/eventos", nil)
+		req.Header.Set(HeaderLastEventId, "g-3")
+		rec := httptest.NewRecorder()
+		NovoRoteador(NewEventosPedidoHandler(&mockPedidoUseCases{}, fonte)).ServeHTTP(rec, req)
+
+		if rec.Code != 200 || rec.Header().Get("Content-Type") != "text/event-stream" {
+			t.Errorf("unexpected response: %d %s", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		expected := "id: g-4\ndata: {\"pedido_id\":1,\"evento\":\"status\",\"status\":\"Pronto\",\"pagamento_aprovado\":true,\"responsavel\":\"cozinha\",\"registrado_em\":\"2024-05-01T12:00:00Z\"}\n\n" +
+			"id: g-5\ndata: {\"pedido_id\":2,\"evento\":\"criacao\",\"status\":\"Recebido\",\"pagamento_aprovado\":false,\"responsavel\":\"cliente\",\"registrado_em\":\"2024-05-01T12:00:00Z\"}\n\n"
+		if rec.Body.String() != expected {
+			t.Errorf("Expected body %q, got %q", expected, rec.Body.String())
+		}
+		if fonte.pedidoId != 0 || fonte.ultimoId != "g-3" || !fonte.cancelada {
+			t.Errorf("unexpected subscription: pedido %d, ultimo id %q, cancelada %v", fonte.pedidoId, fonte.ultimoId, fonte.cancelada)
+		}
+	})
+
+	t.Run("Asks the client to reload when it cannot resume", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NovoRoteador(NewEventosPedidoHandler(&mockPedidoUseCases{}, novaFonte(false))).ServeHTTP(rec, httptest.NewRequest("GET", "/pedido/eventos", nil))
+
+		if rec.Body.String() != "event: reinicio\ndata: {}\n\n" {
+			t.Errorf("unexpected body %q", rec.Body.String())
+		}
+	})
+
+	t.Run("Sends heartbeats until the client disconnects", func(t *testing.T) {
+		fonte := &mockFonteEventos{alteracoes: make(chan entity.AlteracaoPedido), continuo: true}
+		handler := NewEventosPedidoHandler(&mockPedidoUseCases{}, fonte)
+		handler.heartbeat = time.Millisecond
+
+		ctx, cancel := context.WithCancel(context.Background())
+		rec := httptest.NewRecorder()
+		encerrado := make(chan struct{})
+		go func() {
+			NovoRoteador(handler).ServeHTTP(rec, httptest.NewRequest("GET", "/pedido/eventos", nil).WithContext(ctx))
+			close(encerrado)
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		<-encerrado
+
+		if !strings.HasPrefix(rec.Body.String(), ": heartbeat\n\n") {
+			t.Errorf("expected heartbeat comments, got %q", rec.Body.String())
+		}
+		if !fonte.cancelada {
+			t.Error("expected the subscription to be cancelled")
+		}
+	})
+}
+
+func TestEventosPedidoRoute(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		pedidoErr    error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Streams a single pedido",
+			url:          "/pedido/7/eventos",
+			expectedCode: 200,
+			expectedBody: "id: g-1\ndata: {\"pedido_id\":7,\"evento\":\"pagamento\",\"status\":\"Recebido\",\"pagamento_aprovado\":true,\"responsavel\":\"servico-pagamento\",\"registrado_em\":\"0001-01-01T00:00:00Z\"}\n\n",
+		},
+		{
+			name:         "Invalid id",
+			url:          "/pedido/abc/eventos",
+			expectedCode: 400,
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Id de pedido inválido","request_id":""}`,
+		},
+		{
+			name:         "Unknown pedido",
+			url:          "/pedido/99/eventos",
+			pedidoErr:    entity.ErrPedidoNaoEncontrado,
+			expectedCode: 404,
+			expectedBody: `{"status":404,"codigo":"pedido_nao_encontrado","mensagem":"Pedido não encontrado","request_id":""}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fonte := novaFonte(true, entity.AlteracaoPedido{Id: "g-1", PedidoId: 7, Evento: entity.EventoPagamento, Status: entity.StatusRecebido, PagamentoAprovado: true, Responsavel: entity.ResponsavelPagamento})
+			mockUsecase := &mockPedidoUseCases{PedidoErr: test.pedidoErr}
+
+			rec := httptest.NewRecorder()
+			NovoRoteador(NewEventosPedidoHandler(mockUsecase, fonte)).ServeHTTP(rec, httptest.NewRequest("GET", test.url, nil))
+
+			if rec.Code != test.expectedCode {
+				t.Errorf("Expected status code %d, got %d", test.expectedCode, rec.Code)
+			}
+			if rec.Body.String() != test.expectedBody {
+				t.Errorf("Expected body %q, got %q", test.expectedBody, rec.Body.String())
+			}
+			if test.expectedCode == 200 && fonte.pedidoId != 7 {
+				t.Errorf("expected a subscription to pedido 7, got %d", fonte.pedidoId)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gomesmatheus/tc-pedido/usecase"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// comIdempotencia grava a resposta de next para a Idempotency-Key informada, devolvendo a
+// mesma resposta quando a requisição é repetida. Requisições sem o cabeçalho seguem direto.
+// Respostas 5xx não são gravadas, para que o cliente possa tentar novamente com a mesma chave.
+func comIdempotencia(idempotencia usecase.IdempotenciaUseCases, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chave := r.Header.Get(HeaderIdempotencyKey)
+		if idempotencia == nil || chave == "" {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		registro, err := idempotencia.Iniciar(r.Context(), chave, hashRequisicao(r, body))
+		if err != nil {
+			escreverErro(w, r, err)
+			return
+		}
+		if registro != nil {
+			w.Header().Set("Content-Type", registro.ContentType)
+			w.Header().Set(HeaderIdempotentReplayed, "true")
+			w.WriteHeader(registro.Status)
+			w.Write(registro.Corpo)
+			return
+		}
+
+		resposta := &respostaGravada{ResponseWriter: w, status: http.StatusOK}
+		next(resposta, r)
+
+		// A resposta já foi enviada; a gravação não deve ser interrompida pelo fim da requisição.
+		ctx := context.WithoutCancel(r.Context())
+		if resposta.status >= http.StatusInternalServerError {
+			err = idempotencia.Liberar(ctx, chave)
+		} else {
+			err = idempotencia.Concluir(ctx, chave, resposta.status, w.Header().Get("Content-Type"), resposta.corpo.Bytes())
+		}
+		if err != nil {
+			slog.Error("Erro ao gravar resposta idempotente", "chave", chave, "request_id", RequestId(r.Context()), "erro", err)
+		}
+	}
+}
+
+// hashRequisicao identifica o conteúdo da requisição, para detectar a mesma chave reutilizada
+// com outro corpo ou em outra rota.
+func hashRequisicao(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// respostaGravada repassa a resposta ao cliente guardando uma cópia do status e do corpo.
+type respostaGravada struct {
+	http.ResponseWriter
+	status      int
+	corpo       bytes.Buffer
+	cabecalhoOk bool
+}
+
+func (r *respostaGravada) WriteHeader(status int) {
+	if !r.cabecalhoOk {
+		r.status = status
+		r.cabecalhoOk = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *respostaGravada) Write(b []byte) (int, error) {
+	r.cabecalhoOk = true
+	r.corpo.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+// mockIdempotenciaUseCases guarda as respostas em memória com as mesmas regras do caso de uso.
+type mockIdempotenciaUseCases struct {
+	registros  map[string]entity.Idempotencia
+	IniciarErr error
+}
+
+func (m *mockIdempotenciaUseCases) Iniciar(ctx context.Context, chave string, hash string) (*entity.Idempotencia, error) {
+	if m.IniciarErr != nil {
+		return nil, m.IniciarErr
+	}
+	registro, ok := m.registros[chave]
+	if !ok {
+		m.registros[chave] = entity.Idempotencia{Chave: chave, Hash: hash}
+		return nil, nil
+	}
+	if registro.Hash != hash {
+		return nil, entity.ErrChaveIdempotenciaReutilizada
+	}
+	if !registro.Concluida() {
+		return nil, entity.ErrRequisicaoEmAndamento
+	}
+	return &registro, nil
+}
+
+func (m *mockIdempotenciaUseCases) Concluir(ctx context.Context, chave string, status int, contentType string, corpo []byte) error {
+	registro := m.registros[chave]
+	registro.Status, registro.ContentType, registro.Corpo = status, contentType, corpo
+	m.registros[chave] = registro
+	return nil
+}
+
+func (m *mockIdempotenciaUseCases) Liberar(ctx context.Context, chave string) error {
+	delete(m.registros, chave)
+	return nil
+}
+
+func TestCriacaoPedidoIdempotente(t *testing.T) {
+	const corpo = `{"cpf":12345,"metodo_pagamento":"card"}`
+
+	novoCenario := func() (*mockPedidoUseCases, *mockIdempotenciaUseCases, *Roteador) {
+		pedidos := &mockPedidoUseCases{CreateResult: entity.Pedido{Id: 1, Cpf: 12345, Status: entity.StatusRecebido, MetodoPagamento: "card"}}
+		idempotencia := &mockIdempotenciaUseCases{registros: map[string]entity.Idempotencia{}}
+		return pedidos, idempotencia, NovoRoteador(NewPedidoHandler(pedidos, idempotencia, ""))
+	}
+	enviar := func(roteador *Roteador, chave, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/pedido", strings.NewReader(body))
+		if chave != "" {
+			req.Header.Set(HeaderIdempotencyKey, chave)
+		}
+		rec := httptest.NewRecorder()
+		roteador.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Replay returns the original response", func(t *testing.T) {
+		pedidos, _, roteador := novoCenario()
+
+		primeira := enviar(roteador, "chave-1", corpo)
+		segunda := enviar(roteador, "chave-1", corpo)
+
+		if primeira.Code != 201 || segunda.Code != 201 || primeira.Body.String() != segunda.Body.String() {
+			t.Fatalf("expected the same response twice, got %d %s and %d %s", primeira.Code, primeira.Body.String(), segunda.Code, segunda.Body.String())
+		}
+		if segunda.Header().Get("Content-Type") != "application/json" || segunda.Header().Get(HeaderIdempotentReplayed) != "true" {
+			t.Errorf("unexpected replay headers: %v", segunda.Header())
+		}
+		if primeira.Header().Get(HeaderIdempotentReplayed) != "" {
+			t.Errorf("expected the first response not to be marked as replayed")
+		}
+		if pedidos.Criados != 1 {
+			t.Errorf("expected the pedido to be created once, got %d", pedidos.Criados)
+		}
+	})
+
+	t.Run("Same key with another body", func(t *testing.T) {
+		pedidos, _, roteador := novoCenario()
+
+		enviar(roteador, "chave-1", corpo)
+		rec := enviar(roteador, "chave-1", `{"cpf":999,"metodo_pagamento":"card"}`)
+
+		expected := `{"status":422,"codigo":"chave_idempotencia_reutilizada","mensagem":"Idempotency-Key já usada com outra requisição","request_id":""}`
+		if rec.Code != 422 || rec.Body.String() != expected {
+			t.Errorf("unexpected response: %d %s", rec.Code, rec.Body.String())
+		}
+		if pedidos.Criados != 1 {
+			t.Errorf("expected the pedido to be created once, got %d", pedidos.Criados)
+		}
+	})
+
+	t.Run("Validation errors are replayed too", func(t *testing.T) {
+		pedidos, _, roteador := novoCenario()
+		pedidos.CreateErr = entity.ErrPedidoInvalido
+
+		primeira := enviar(roteador, "chave-1", corpo)
+		segunda := enviar(roteador, "chave-1", corpo)
+
+		if primeira.Code != 422 || segunda.Code != 422 || pedidos.Criados != 1 {
+			t.Errorf("expected the 422 to be replayed, got %d, %d after %d calls", primeira.Code, segunda.Code, pedidos.Criados)
+		}
+	})
+
+	t.Run("Server errors release the key", func(t *testing.T) {
+		pedidos, _, roteador := novoCenario()
+		pedidos.CreateErr = errors.New("db down")
+
+		if rec := enviar(roteador, "chave-1", corpo); rec.Code != 500 {
+			t.Fatalf("expected 500, got %d", rec.Code)
+		}
+
+		pedidos.CreateErr = nil
+		if rec := enviar(roteador, "chave-1", corpo); rec.Code != 201 || rec.Header().Get(HeaderIdempotentReplayed) != "" {
+			t.Errorf("expected a fresh attempt after the failure, got %d", rec.Code)
+		}
+		if pedidos.Criados != 2 {
+			t.Errorf("expected the pedido to be attempted twice, got %d", pedidos.Criados)
+		}
+	})
+
+	t.Run("Request still in progress", func(t *testing.T) {
+		_, idempotencia, roteador := novoCenario()
+		idempotencia.IniciarErr = entity.ErrRequisicaoEmAndamento
+
+		if rec := enviar(roteador, "chave-1", corpo); rec.Code != 409 {
+			t.Errorf("expected 409, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Without the header every request is processed", func(t *testing.T) {
+		pedidos, idempotencia, roteador := novoCenario()
+
+		enviar(roteador, "", corpo)
+		enviar(roteador, "", corpo)
+
+		if pedidos.Criados != 2 || len(idempotencia.registros) != 0 {
+			t.Errorf("expected 2 pedidos and no stored keys, got %d and %d", pedidos.Criados, len(idempotencia.registros))
+		}
+	})
+}
@@ -1,71 +1,106 @@
 package handlers
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/gomesmatheus/tc-pedido/domain/entity"
 	"github.com/gomesmatheus/tc-pedido/usecase"
 )
 
+// HeaderAssinaturaPagamento traz o HMAC-SHA256 do corpo da notificação de pagamento,
+// em hexadecimal, calculado com o segredo compartilhado com o serviço de pagamentos.
+const HeaderAssinaturaPagamento = "X-Assinatura-Pagamento"
+
 type PedidoHandler struct {
-	pedidoUseCases usecase.PedidoUseCases
+	pedidoUseCases   usecase.PedidoUseCases
+	idempotencia     usecase.IdempotenciaUseCases
+	segredoPagamento []byte
 }
 
 type PatchPedido struct {
-	Status string `json:"status"`
+	Status      entity.StatusPedido `json:"status"`
+	Responsavel string              `json:"responsavel"`
+}
+
+type SolicitacaoCancelamento struct {
+	Motivo       string `json:"motivo"`
+	CanceladoPor string `json:"cancelado_por"`
+}
+
+type NotificacaoPagamento struct {
+	PedidoId int  `json:"pedido_id"`
+	Aprovado bool `json:"aprovado"`
 }
 
-func NewPedidoHandler(pedidoUseCases usecase.PedidoUseCases) *PedidoHandler {
+// NewPedidoHandler recebe idempotencia opcional; sem ela o cabeçalho Idempotency-Key é ignorado.
+// Sem segredoPagamento, todas as notificações de pagamento são recusadas.
+func NewPedidoHandler(pedidoUseCases usecase.PedidoUseCases, idempotencia usecase.IdempotenciaUseCases, segredoPagamento string) *PedidoHandler {
 	return &PedidoHandler{
-		pedidoUseCases: pedidoUseCases,
+		pedidoUseCases:   pedidoUseCases,
+		idempotencia:     idempotencia,
+		segredoPagamento: []byte(segredoPagamento),
 	}
 }
 
+func (c *PedidoHandler) Registrar(mux Mux) {
+	mux.HandleFunc("POST /pedido", comIdempotencia(c.idempotencia, c.CriacaoPedidoRoute))
+	mux.HandleFunc("GET /pedido", c.CriacaoPedidoRoute)
+	mux.HandleFunc("GET /pedido/fila", c.FilaCozinhaRoute)
+	mux.HandleFunc("GET /pedido/{id}", c.PedidoPorIdRoute)
+	mux.HandleFunc("GET /pedido/{id}/historico", c.HistoricoPedidoRoute)
+	mux.HandleFunc("PATCH /pedido/{id}/status", c.AtualizarPedidoRoute)
+	mux.HandleFunc("POST /pedido/{id}/cancelamento", comIdempotencia(c.idempotencia, c.CancelarPedidoRoute))
+	mux.HandleFunc("POST /pedido/pagamento", c.WebhookPagamentoRoute)
+}
+
 func (c *PedidoHandler) CriacaoPedidoRoute(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "POST" {
 		body, err := io.ReadAll(r.Body)
 		defer r.Body.Close()
 		if err != nil {
-			fmt.Println("Error parsing request body")
-			w.WriteHeader(400)
-			w.Write([]byte("400 bad request"))
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
 			return
 		}
 
 		var pedido entity.Pedido
 		err = json.Unmarshal(body, &pedido)
 		if err != nil {
-			fmt.Println("Error parsing request body")
-			w.WriteHeader(400)
-			w.Write([]byte("400 bad request"))
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
 			return
 		}
 		fmt.Println(pedido)
 
-		pedido, err = c.pedidoUseCases.CriarPedido(pedido)
+		pedido, err = c.pedidoUseCases.CriarPedido(r.Context(), pedido)
 		if err != nil {
-			fmt.Println("Erro ao cadastrar o pedido", err)
-			w.WriteHeader(500)
-			w.Write([]byte("Erro ao cadastrar o pedido"))
+			escreverErro(w, r, err)
 			return
 		}
 
+		response, _ := json.Marshal(pedido)
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(201)
-		w.Write([]byte(fmt.Sprintf("Pedido inserido com id %d", pedido.Id)))
+		w.Write(response)
 	} else if r.Method == "GET" {
-		pedidos, err := c.pedidoUseCases.RecuperarPedidos()
+		filtro, err := lerFiltroPedidos(r)
 		if err != nil {
-			fmt.Println("Erro ao recuperar pedidos", err)
-			w.WriteHeader(500)
-			w.Write([]byte("Erro ao recuperar pedidos"))
+			escreverErro(w, r, err)
 			return
 		}
-		response, _ := json.Marshal(pedidos)
+
+		pagina, err := c.pedidoUseCases.RecuperarPedidos(r.Context(), filtro)
+		if err != nil {
+			escreverErro(w, r, err)
+			return
+		}
+		response, _ := json.Marshal(pagina)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(200)
 		w.Write(response)
@@ -74,12 +109,62 @@ func (c *PedidoHandler) CriacaoPedidoRoute(w http.ResponseWriter, r *http.Reques
 	return
 }
 
+func (c *PedidoHandler) FilaCozinhaRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" {
+		pedidos, err := c.pedidoUseCases.RecuperarFilaCozinha(r.Context())
+		if err != nil {
+			escreverErro(w, r, err)
+			return
+		}
+		response, _ := json.Marshal(pedidos)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		w.Write(response)
+	}
+}
+
+func (c *PedidoHandler) PedidoPorIdRoute(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		requisicaoInvalida(w, r, "Id de pedido inválido")
+		return
+	}
+
+	pedido, err := c.pedidoUseCases.RecuperarPedido(r.Context(), int(id))
+	if err != nil {
+		escreverErro(w, r, err)
+		return
+	}
+
+	response, _ := json.Marshal(pedido)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(response)
+}
+
+func (c *PedidoHandler) HistoricoPedidoRoute(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		requisicaoInvalida(w, r, "Id de pedido inválido")
+		return
+	}
+
+	historico, err := c.pedidoUseCases.RecuperarHistorico(r.Context(), int(id))
+	if err != nil {
+		escreverErro(w, r, err)
+		return
+	}
+
+	response, _ := json.Marshal(historico)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(response)
+}
+
 func (c *PedidoHandler) AtualizarPedidoRoute(w http.ResponseWriter, r *http.Request) {
-	id, err := strconv.ParseInt(strings.Split(r.URL.Path, "/")[3], 10, 64)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		fmt.Println("Error parsing request body")
-		w.WriteHeader(400)
-		w.Write([]byte("400 bad request"))
+		requisicaoInvalida(w, r, "Id de pedido inválido")
 		return
 	}
 	if r.Method == "PATCH" {
@@ -87,24 +172,18 @@ func (c *PedidoHandler) AtualizarPedidoRoute(w http.ResponseWriter, r *http.Requ
 		body, err := io.ReadAll(r.Body)
 		defer r.Body.Close()
 		if err != nil {
-			fmt.Println("Error parsing request body")
-			w.WriteHeader(400)
-			w.Write([]byte("400 bad request"))
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
 			return
 		}
 		err = json.Unmarshal(body, &patchPedido)
 		if err != nil {
-			fmt.Println("Error parsing request body")
-			w.WriteHeader(400)
-			w.Write([]byte("400 bad request"))
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
 			return
 		}
 
-		err = c.pedidoUseCases.AtualizarStatus(int(id), patchPedido.Status)
+		err = c.pedidoUseCases.AtualizarStatus(r.Context(), int(id), patchPedido.Status, patchPedido.Responsavel)
 		if err != nil {
-			fmt.Println("Erro ao atualizar o pedido", err)
-			w.WriteHeader(500)
-			w.Write([]byte("Erro ao atualizar o pedido"))
+			escreverErro(w, r, err)
 			return
 		}
 
@@ -112,3 +191,154 @@ func (c *PedidoHandler) AtualizarPedidoRoute(w http.ResponseWriter, r *http.Requ
 		w.Write([]byte("Pedido atualizado"))
 	}
 }
+
+func (c *PedidoHandler) CancelarPedidoRoute(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		requisicaoInvalida(w, r, "Id de pedido inválido")
+		return
+	}
+
+	var solicitacao SolicitacaoCancelamento
+	body, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		requisicaoInvalida(w, r, "Corpo da requisição inválido")
+		return
+	}
+	if err = json.Unmarshal(body, &solicitacao); err != nil {
+		requisicaoInvalida(w, r, "Corpo da requisição inválido")
+		return
+	}
+
+	cancelamento, err := c.pedidoUseCases.CancelarPedido(r.Context(), int(id), solicitacao.Motivo, solicitacao.CanceladoPor)
+	if err != nil {
+		escreverErro(w, r, err)
+		return
+	}
+
+	response, _ := json.Marshal(cancelamento)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(response)
+}
+
+func (c *PedidoHandler) WebhookPagamentoRoute(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "POST" {
+		var notificacao NotificacaoPagamento
+		body, err := io.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
+			return
+		}
+		if !c.assinaturaValida(body, r.Header.Get(HeaderAssinaturaPagamento)) {
+			escreverErro(w, r, entity.ErrAssinaturaInvalida)
+			return
+		}
+		err = json.Unmarshal(body, &notificacao)
+		if err != nil {
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
+			return
+		}
+		if notificacao.PedidoId == 0 {
+			escreverErro(w, r, entity.ErrPedidoInvalido.ComCampos(entity.ErroCampo{Campo: "pedido_id", Mensagem: "obrigatório"}))
+			return
+		}
+
+		err = c.pedidoUseCases.ConfirmarPagamento(r.Context(), notificacao.PedidoId, notificacao.Aprovado)
+		if err != nil {
+			escreverErro(w, r, err)
+			return
+		}
+
+		w.WriteHeader(200)
+		w.Write([]byte("Pagamento processado"))
+	}
+}
+
+// assinaturaValida confere o HMAC do corpo da notificação em tempo constante.
+func (c *PedidoHandler) assinaturaValida(body []byte, assinatura string) bool {
+	if len(c.segredoPagamento) == 0 {
+		return false
+	}
+	recebida, err := hex.DecodeString(assinatura)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, c.segredoPagamento)
+	mac.Write(body)
+	return hmac.Equal(recebida, mac.Sum(nil))
+}
+
+// lerFiltroPedidos converte os parâmetros de consulta de GET /pedido. Aqui só são tratados
+// erros de formato; a consistência do filtro é validada pelo caso de uso.
+func lerFiltroPedidos(r *http.Request) (entity.FiltroPedidos, error) {
+	query := r.URL.Query()
+	filtro := entity.FiltroPedidos{
+		Status:    entity.StatusPedido(query.Get("status")),
+		Ordenacao: entity.OrdenacaoPedidos(query.Get("ordenar")),
+	}
+	var campos []entity.ErroCampo
+
+	if v := query.Get("cpf"); v != "" {
+		cpf, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			campos = append(campos, entity.ErroCampo{Campo: "cpf", Mensagem: "deve ser numérico"})
+		}
+		filtro.Cpf = cpf
+	}
+	if v := query.Get("pagamento_aprovado"); v != "" {
+		aprovado, err := strconv.ParseBool(v)
+		if err != nil {
+			campos = append(campos, entity.ErroCampo{Campo: "pagamento_aprovado", Mensagem: "use true ou false"})
+		}
+		filtro.PagamentoAprovado = &aprovado
+	}
+	if v := query.Get("criado_de"); v != "" {
+		de, err := lerData(v, false)
+		if err != nil {
+			campos = append(campos, entity.ErroCampo{Campo: "criado_de", Mensagem: "use RFC 3339 ou AAAA-MM-DD"})
+		}
+		filtro.CriadoDe = &de
+	}
+	if v := query.Get("criado_ate"); v != "" {
+		ate, err := lerData(v, true)
+		if err != nil {
+			campos = append(campos, entity.ErroCampo{Campo: "criado_ate", Mensagem: "use RFC 3339 ou AAAA-MM-DD"})
+		}
+		filtro.CriadoAte = &ate
+	}
+	if v := query.Get("limite"); v != "" {
+		limite, err := strconv.Atoi(v)
+		if err != nil {
+			campos = append(campos, entity.ErroCampo{Campo: "limite", Mensagem: "deve ser numérico"})
+		}
+		filtro.Limite = limite
+	}
+	if v := query.Get("cursor"); v != "" {
+		cursor, err := entity.DecodificarCursorPedidos(v)
+		if err != nil {
+			campos = append(campos, entity.ErroCampo{Campo: "cursor", Mensagem: "cursor inválido"})
+		}
+		filtro.Cursor = &cursor
+	}
+
+	if len(campos) > 0 {
+		return filtro, entity.ErrFiltroInvalido.ComCampos(campos...)
+	}
+	return filtro, nil
+}
+
+// lerData aceita um instante em RFC 3339 ou uma data. Como limite final, uma data
+// cobre o dia inteiro.
+func lerData(v string, fimDoDia bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err == nil && fimDoDia {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, err
+}
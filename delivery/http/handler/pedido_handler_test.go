@@ -2,34 +2,79 @@ package handlers
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gomesmatheus/tc-pedido/domain/entity"
 )
 
 type mockPedidoUseCases struct {
 	CreateResult    entity.Pedido
+	Criados         int
 	FetchPedidos    []entity.Pedido
+	ProximoCursor   string
+	Filtro          entity.FiltroPedidos
 	UpdatedStatus   error
+	Responsavel     string
 	FetchPedidosErr error
 	CreateErr       error
+	PagamentoErr    error
+	Pagamentos      int
+	Pedido          entity.Pedido
+	PedidoErr       error
+	FilaCozinha     []entity.Pedido
+	FilaCozinhaErr  error
+	Cancelamento    entity.Cancelamento
+	CancelarErr     error
+	Historico       []entity.HistoricoPedido
+	HistoricoErr    error
 }
 
-func (m *mockPedidoUseCases) CriarPedido(p entity.Pedido) (entity.Pedido, error) {
+func (m *mockPedidoUseCases) CriarPedido(ctx context.Context, p entity.Pedido) (entity.Pedido, error) {
+	m.Criados++
 	return m.CreateResult, m.CreateErr
 }
 
-func (m *mockPedidoUseCases) RecuperarPedidos() ([]entity.Pedido, error) {
-	return m.FetchPedidos, m.FetchPedidosErr
+func (m *mockPedidoUseCases) RecuperarPedidos(ctx context.Context, filtro entity.FiltroPedidos) (entity.PaginaPedidos, error) {
+	m.Filtro = filtro
+	return entity.PaginaPedidos{Pedidos: m.FetchPedidos, ProximoCursor: m.ProximoCursor}, m.FetchPedidosErr
 }
 
-func (m *mockPedidoUseCases) AtualizarStatus(id int, status string) error {
+func (m *mockPedidoUseCases) RecuperarPedido(ctx context.Context, id int) (entity.Pedido, error) {
+	return m.Pedido, m.PedidoErr
+}
+
+func (m *mockPedidoUseCases) RecuperarFilaCozinha(ctx context.Context) ([]entity.Pedido, error) {
+	return m.FilaCozinha, m.FilaCozinhaErr
+}
+
+func (m *mockPedidoUseCases) AtualizarStatus(ctx context.Context, id int, status entity.StatusPedido, responsavel string) error {
+	m.Responsavel = responsavel
 	return m.UpdatedStatus
 }
 
+func (m *mockPedidoUseCases) ConfirmarPagamento(ctx context.Context, id int, aprovado bool) error {
+	m.Pagamentos++
+	return m.PagamentoErr
+}
+
+func (m *mockPedidoUseCases) CancelarPedido(ctx context.Context, id int, motivo string, canceladoPor string) (entity.Cancelamento, error) {
+	m.Cancelamento.PedidoId, m.Cancelamento.Motivo, m.Cancelamento.CanceladoPor = id, motivo, canceladoPor
+	return m.Cancelamento, m.CancelarErr
+}
+
+func (m *mockPedidoUseCases) RecuperarHistorico(ctx context.Context, id int) ([]entity.HistoricoPedido, error) {
+	return m.Historico, m.HistoricoErr
+}
+
 func TestCriacaoPedidoRoute(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -43,40 +88,56 @@ func TestCriacaoPedidoRoute(t *testing.T) {
 			method:       "POST",
 			body:         `{"cpf":12345,"status":"Pending","metodo_pagamento":"card"}`,
 			expectedCode: 201,
-			expectedBody: "Pedido inserido com id 1",
+			expectedBody: `{"id":1,"cpf":12345,"produtos":[{"id":10,"produto_id":1,"quantidade":2,"observacao":"","preco_unitario":{"valor":10.00,"moeda":"BRL"},"subtotal":{"valor":20.00,"moeda":"BRL"}}],"status":"Recebido","metodo_de_pagamento":"card","pagamento_aprovado":false,"total":{"valor":20.00,"moeda":"BRL"},"tempo_de_preparo":0,"previsao_pronto":null,"criado_em":"0001-01-01T00:00:00Z"}`,
 		},
 		{
 			name:         "POST with invalid JSON",
 			method:       "POST",
 			body:         "{invalid-json}",
 			expectedCode: 400,
-			expectedBody: "400 bad request",
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Corpo da requisição inválido","request_id":""}`,
 		},
 		{
 			name:         "POST with internal error",
 			method:       "POST",
 			body:         `{"cpf":12345,"status":"Pending","metodo_pagamento":"card"}`,
 			expectedCode: 500,
-			expectedBody: "Erro ao cadastrar o pedido",
+			expectedBody: `{"status":500,"codigo":"erro_interno","mensagem":"Erro interno ao processar a requisição","request_id":""}`,
+		},
+		{
+			name:         "POST with cliente service down",
+			method:       "POST",
+			body:         `{"cpf":12345,"status":"Pending","metodo_pagamento":"card"}`,
+			expectedCode: 503,
+			expectedBody: `{"status":503,"codigo":"cliente_indisponivel","mensagem":"Serviço de clientes indisponível: timeout","request_id":""}`,
+		},
+		{
+			name:         "POST with invalid items",
+			method:       "POST",
+			body:         `{"cpf":12345,"metodo_pagamento":"card","produtos":[{"produto_id":9999,"quantidade":1},{"produto_id":1,"quantidade":0}]}`,
+			expectedCode: 422,
+			expectedBody: `{"status":422,"codigo":"pedido_invalido","mensagem":"Pedido inválido","campos":[{"campo":"produtos[0].produto_id","mensagem":"produto 9999 não encontrado"},{"campo":"produtos[1].quantidade","mensagem":"deve estar entre 1 e 99"}],"request_id":""}`,
 		},
 		{
 			name:         "Successful GET",
 			method:       "GET",
 			expectedCode: 200,
-			expectedBody: `[{"id":1,"cpf":12345,"produtos":null,"status":"Pending","metodo_de_pagamento":"card","pagamento_aprovado":false}]`,
+			expectedBody: `{"pedidos":[{"id":1,"cpf":12345,"produtos":null,"status":"Pending","metodo_de_pagamento":"card","pagamento_aprovado":false,"total":{"valor":0.00,"moeda":"BRL"},"tempo_de_preparo":0,"previsao_pronto":null,"criado_em":"0001-01-01T00:00:00Z"}]}`,
 		},
 		{
 			name:         "GET with internal error",
 			method:       "GET",
 			expectedCode: 500,
-			expectedBody: "Erro ao recuperar pedidos",
+			expectedBody: `{"status":500,"codigo":"erro_interno","mensagem":"Erro interno ao processar a requisição","request_id":""}`,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			mockUsecase := &mockPedidoUseCases{
-				CreateResult: entity.Pedido{Id: 1, Cpf: 12345, Status: "Pending", MetodoPagamento: "card"},
+				CreateResult: entity.Pedido{Id: 1, Cpf: 12345, Status: "Recebido", MetodoPagamento: "card", Total: entity.Centavos(2000), Produtos: []entity.ProdutoPedido{
+					{Id: 10, ProdutoId: 1, Quantidade: 2, PrecoUnitario: entity.Centavos(1000), Subtotal: entity.Centavos(2000)},
+				}},
 				FetchPedidos: []entity.Pedido{{Id: 1, Cpf: 12345, Status: "Pending", MetodoPagamento: "card"}},
 				CreateErr:    nil,
 			}
@@ -84,11 +145,18 @@ func TestCriacaoPedidoRoute(t *testing.T) {
 			// Simulate errors for specific test cases
 			if test.name == "POST with internal error" {
 				mockUsecase.CreateErr = fmt.Errorf("internal error")
+			} else if test.name == "POST with cliente service down" {
+				mockUsecase.CreateErr = fmt.Errorf("%w: timeout", entity.ErrClienteIndisponivel)
+			} else if test.name == "POST with invalid items" {
+				mockUsecase.CreateErr = entity.ErrPedidoInvalido.ComCampos(
+					entity.ErroCampo{Campo: "produtos[0].produto_id", Mensagem: "produto 9999 não encontrado"},
+					entity.ErroCampo{Campo: "produtos[1].quantidade", Mensagem: "deve estar entre 1 e 99"},
+				)
 			} else if test.name == "GET with internal error" {
 				mockUsecase.FetchPedidosErr = fmt.Errorf("internal error")
 			}
 
-			handler := NewPedidoHandler(mockUsecase)
+			handler := NewPedidoHandler(mockUsecase, nil, "")
 
 			req := httptest.NewRequest(test.method, "/pedidos", bytes.NewBuffer([]byte(test.body)))
 			rec := httptest.NewRecorder()
@@ -110,6 +178,101 @@ func TestCriacaoPedidoRoute(t *testing.T) {
 	}
 }
 
+func TestRecuperarPedidosFiltro(t *testing.T) {
+	cursor := entity.CursorPedidos{Ordenacao: entity.OrdenarPorDataDesc, CriadoEm: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), Id: 7}
+
+	t.Run("Query parameters become the filter", func(t *testing.T) {
+		mockUsecase := &mockPedidoUseCases{FetchPedidos: []entity.Pedido{}, ProximoCursor: "abc"}
+		handler := NewPedidoHandler(mockUsecase, nil, "")
+
+		url := "/pedido?status=Pronto&cpf=12345&pagamento_aprovado=true&criado_de=2024-05-01&criado_ate=2024-05-31&ordenar=-data&limite=10&cursor=" + cursor.Codificar()
+		rec := httptest.NewRecorder()
+		NovoRoteador(handler).ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+
+		if rec.Code != 200 || rec.Body.String() != `{"pedidos":[],"proximo_cursor":"abc"}` {
+			t.Fatalf("unexpected response: %d %s", rec.Code, rec.Body.String())
+		}
+
+		f := mockUsecase.Filtro
+		if f.Status != entity.StatusPronto || f.Cpf != 12345 || f.PagamentoAprovado == nil || !*f.PagamentoAprovado {
+			t.Errorf("unexpected filter: %+v", f)
+		}
+		if f.Ordenacao != entity.OrdenarPorDataDesc || f.Limite != 10 || f.Cursor == nil || *f.Cursor != cursor {
+			t.Errorf("unexpected pagination: %+v", f)
+		}
+		if !f.CriadoDe.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) || !f.CriadoAte.Equal(time.Date(2024, 6, 1, 0, 0, 0, -1, time.UTC)) {
+			t.Errorf("unexpected date range: %v - %v", f.CriadoDe, f.CriadoAte)
+		}
+	})
+
+	t.Run("Malformed parameters", func(t *testing.T) {
+		handler := NewPedidoHandler(&mockPedidoUseCases{}, nil, "")
+
+		rec := httptest.NewRecorder()
+		NovoRoteador(handler).ServeHTTP(rec, httptest.NewRequest("GET", "/pedido?cpf=abc&limite=dez&cursor=%25", nil))
+
+		expected := `{"status":422,"codigo":"filtro_invalido","mensagem":"Filtro de pedidos inválido","campos":[{"campo":"cpf","mensagem":"deve ser numérico"},{"campo":"limite","mensagem":"deve ser numérico"},{"campo":"cursor","mensagem":"cursor inválido"}],"request_id":""}`
+		if rec.Code != 422 || rec.Body.String() != expected {
+			t.Errorf("unexpected response: %d %s", rec.Code, rec.Body.String())
+		}
+	})
+}
+
+func TestPedidoPorIdRoute(t *testing.T) {
+	criado := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	previsao := criado.Add(15 * time.Minute)
+
+	tests := []struct {
+		name         string
+		url          string
+		mockErr      error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Successful GET",
+			url:          "/pedido/3",
+			expectedCode: 200,
+			expectedBody: `{"id":3,"cpf":12345,"produtos":[{"id":10,"produto_id":1,"quantidade":2,"observacao":"","preco_unitario":{"valor":10.00,"moeda":"BRL"},"subtotal":{"valor":20.00,"moeda":"BRL"}}],"status":"Em preparação","metodo_de_pagamento":"card","pagamento_aprovado":true,"total":{"valor":20.00,"moeda":"BRL"},"tempo_de_preparo":15,"previsao_pronto":"2024-05-01T12:15:00Z","criado_em":"2024-05-01T12:00:00Z"}`,
+		},
+		{
+			name:         "GET with invalid id",
+			url:          "/pedido/abc",
+			expectedCode: 400,
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Id de pedido inválido","request_id":""}`,
+		},
+		{
+			name:         "GET unknown pedido",
+			url:          "/pedido/99",
+			mockErr:      entity.ErrPedidoNaoEncontrado,
+			expectedCode: 404,
+			expectedBody: `{"status":404,"codigo":"pedido_nao_encontrado","mensagem":"Pedido não encontrado","request_id":""}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewPedidoHandler(&mockPedidoUseCases{
+				Pedido: entity.Pedido{Id: 3, Cpf: 12345, Status: entity.StatusEmPreparacao, MetodoPagamento: "card", PagamentoAprovado: true, Total: entity.Centavos(2000), TempoDePreparo: 15, PrevisaoPronto: &previsao, CriadoEm: criado, Produtos: []entity.ProdutoPedido{
+					{Id: 10, ProdutoId: 1, Quantidade: 2, PrecoUnitario: entity.Centavos(1000), Subtotal: entity.Centavos(2000)},
+				}},
+				PedidoErr: test.mockErr,
+			}, nil, "")
+
+			rec := httptest.NewRecorder()
+			NovoRoteador(handler).ServeHTTP(rec, httptest.NewRequest("GET", test.url, nil))
+
+			if rec.Code != test.expectedCode {
+				t.Errorf("Expected status code %d, got %d", test.expectedCode, rec.Code)
+			}
+
+			if rec.Body.String() != test.expectedBody {
+				t.Errorf("Expected body %q, got %q", test.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
 func TestAtualizarPedidoRoute(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -122,7 +285,7 @@ func TestAtualizarPedidoRoute(t *testing.T) {
 		{
 			name:         "Successful PATCH",
 			method:       "PATCH",
-			body:         `{"status":"Completed"}`,
+			body:         `{"status":"Completed","responsavel":"cozinha"}`,
 			url:          "/pedido/atualizar/1",
 			expectedCode: 201,
 			expectedBody: "Pedido atualizado",
@@ -133,7 +296,7 @@ func TestAtualizarPedidoRoute(t *testing.T) {
 			body:         "{invalid-json}",
 			url:          "/pedido/atualizar/1",
 			expectedCode: 400,
-			expectedBody: "400 bad request",
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Corpo da requisição inválido","request_id":""}`,
 		},
 		{
 			name:         "PATCH with internal error",
@@ -141,7 +304,23 @@ func TestAtualizarPedidoRoute(t *testing.T) {
 			body:         `{"status":"Completed"}`,
 			url:          "/pedido/atualizar/1",
 			expectedCode: 500,
-			expectedBody: "Erro ao atualizar o pedido",
+			expectedBody: `{"status":500,"codigo":"erro_interno","mensagem":"Erro interno ao processar a requisição","request_id":""}`,
+		},
+		{
+			name:         "PATCH with forbidden transition",
+			method:       "PATCH",
+			body:         `{"status":"Finalizado"}`,
+			url:          "/pedido/atualizar/1",
+			expectedCode: 409,
+			expectedBody: `{"status":409,"codigo":"transicao_status_invalida","mensagem":"Transição de status não permitida","request_id":""}`,
+		},
+		{
+			name:         "PATCH with unknown status",
+			method:       "PATCH",
+			body:         `{"status":"pronto "}`,
+			url:          "/pedido/atualizar/1",
+			expectedCode: 422,
+			expectedBody: `{"status":422,"codigo":"status_invalido","mensagem":"Status de pedido inválido","request_id":""}`,
 		},
 		{
 			name:         "PATCH with missing ID",
@@ -149,7 +328,7 @@ func TestAtualizarPedidoRoute(t *testing.T) {
 			body:         `{"status":"Completed"}`,
 			url:          "/pedido/atualizar/abc",
 			expectedCode: 400,
-			expectedBody: "400 bad request",
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Id de pedido inválido","request_id":""}`,
 		},
 	}
 
@@ -162,14 +341,105 @@ func TestAtualizarPedidoRoute(t *testing.T) {
 			// Simulate errors for specific test cases
 			if test.name == "PATCH with internal error" {
 				mockUsecase.UpdatedStatus = fmt.Errorf("internal error")
+			} else if test.name == "PATCH with forbidden transition" {
+				mockUsecase.UpdatedStatus = entity.ErrTransicaoStatusInvalida
+			} else if test.name == "PATCH with unknown status" {
+				mockUsecase.UpdatedStatus = entity.ErrStatusInvalido
 			}
 
-			handler := NewPedidoHandler(mockUsecase)
+			handler := NewPedidoHandler(mockUsecase, nil, "")
 
 			req := httptest.NewRequest(test.method, test.url, bytes.NewBuffer([]byte(test.body)))
 			rec := httptest.NewRecorder()
 
-			handler.AtualizarPedidoRoute(rec, req)
+			NovoRoteador(handler).ServeHTTP(rec, req)
+
+			resp := rec.Result()
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+
+			if resp.StatusCode != test.expectedCode {
+				t.Errorf("Expected status code %d, got %d", test.expectedCode, resp.StatusCode)
+			}
+
+			if string(body) != test.expectedBody {
+				t.Errorf("Expected body %q, got %q", test.expectedBody, string(body))
+			}
+
+			if test.name == "Successful PATCH" && mockUsecase.Responsavel != "cozinha" {
+				t.Errorf("Expected responsavel %q, got %q", "cozinha", mockUsecase.Responsavel)
+			}
+		})
+	}
+}
+
+// assinar calcula o cabeçalho X-Assinatura-Pagamento que o serviço de pagamentos enviaria.
+func assinar(segredo, body string) string {
+	mac := hmac.New(sha256.New, []byte(segredo))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookPagamentoRoute(t *testing.T) {
+	const segredo = "segredo-de-teste"
+
+	tests := []struct {
+		name         string
+		body         string
+		mockErr      error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Approved payment",
+			body:         `{"pedido_id":1,"aprovado":true}`,
+			expectedCode: 200,
+			expectedBody: "Pagamento processado",
+		},
+		{
+			name:         "Invalid JSON",
+			body:         "{invalid-json}",
+			expectedCode: 400,
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Corpo da requisição inválido","request_id":""}`,
+		},
+		{
+			name:         "Missing pedido id",
+			body:         `{"aprovado":true}`,
+			expectedCode: 422,
+			expectedBody: `{"status":422,"codigo":"pedido_invalido","mensagem":"Pedido inválido","campos":[{"campo":"pedido_id","mensagem":"obrigatório"}],"request_id":""}`,
+		},
+		{
+			name:         "Unknown pedido",
+			body:         `{"pedido_id":99,"aprovado":true}`,
+			mockErr:      entity.ErrPedidoNaoEncontrado,
+			expectedCode: 404,
+			expectedBody: `{"status":404,"codigo":"pedido_nao_encontrado","mensagem":"Pedido não encontrado","request_id":""}`,
+		},
+		{
+			name:         "Conflicting notification",
+			body:         `{"pedido_id":1,"aprovado":false}`,
+			mockErr:      entity.ErrPagamentoJaProcessado,
+			expectedCode: 409,
+			expectedBody: `{"status":409,"codigo":"pagamento_ja_processado","mensagem":"Pagamento do pedido já foi processado com outro resultado","request_id":""}`,
+		},
+		{
+			name:         "Internal error",
+			body:         `{"pedido_id":1,"aprovado":true}`,
+			mockErr:      fmt.Errorf("internal error"),
+			expectedCode: 500,
+			expectedBody: `{"status":500,"codigo":"erro_interno","mensagem":"Erro interno ao processar a requisição","request_id":""}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewPedidoHandler(&mockPedidoUseCases{PagamentoErr: test.mockErr}, nil, segredo)
+
+			req := httptest.NewRequest("POST", "/pedido/pagamento", bytes.NewBuffer([]byte(test.body)))
+			req.Header.Set(HeaderAssinaturaPagamento, assinar(segredo, test.body))
+			rec := httptest.NewRecorder()
+
+			handler.WebhookPagamentoRoute(rec, req)
 
 			resp := rec.Result()
 			defer resp.Body.Close()
@@ -184,4 +454,215 @@ func TestAtualizarPedidoRoute(t *testing.T) {
 			}
 		})
 	}
+
+	rejeitadas := []struct {
+		name       string
+		segredo    string
+		assinatura string
+	}{
+		{name: "Missing signature", segredo: segredo},
+		{name: "Signature with another secret", segredo: segredo, assinatura: assinar("outro-segredo", `{"pedido_id":1,"aprovado":true}`)},
+		{name: "Signature of another body", segredo: segredo, assinatura: assinar(segredo, `{"pedido_id":2,"aprovado":true}`)},
+		{name: "Malformed signature", segredo: segredo, assinatura: "não é hexadecimal"},
+		{name: "No secret configured", assinatura: assinar("", `{"pedido_id":1,"aprovado":true}`)},
+	}
+
+	for _, test := range rejeitadas {
+		t.Run(test.name, func(t *testing.T) {
+			mockUsecase := &mockPedidoUseCases{}
+			req := httptest.NewRequest("POST", "/pedido/pagamento", strings.NewReader(`{"pedido_id":1,"aprovado":true}`))
+			req.Header.Set(HeaderAssinaturaPagamento, test.assinatura)
+			rec := httptest.NewRecorder()
+
+			NewPedidoHandler(mockUsecase, nil, test.segredo).WebhookPagamentoRoute(rec, req)
+
+			expected := `{"status":401,"codigo":"assinatura_invalida","mensagem":"Assinatura da notificação de pagamento inválida","request_id":""}`
+			if rec.Code != 401 || rec.Body.String() != expected {
+				t.Errorf("Expected 401 %s, got %d %s", expected, rec.Code, rec.Body.String())
+			}
+			if mockUsecase.Pagamentos != 0 {
+				t.Errorf("expected the notification not to be processed, got %d calls", mockUsecase.Pagamentos)
+			}
+		})
+	}
+}
+
+func TestFilaCozinhaRoute(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockErr      error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Successful GET",
+			expectedCode: 200,
+			expectedBody: `[{"id":2,"cpf":12345,"produtos":null,"status":"Pronto","metodo_de_pagamento":"card","pagamento_aprovado":true,"total":{"valor":0.00,"moeda":"BRL"},"tempo_de_preparo":0,"previsao_pronto":null,"criado_em":"0001-01-01T00:00:00Z"}]`,
+		},
+		{
+			name:         "GET with internal error",
+			mockErr:      fmt.Errorf("internal error"),
+			expectedCode: 500,
+			expectedBody: `{"status":500,"codigo":"erro_interno","mensagem":"Erro interno ao processar a requisição","request_id":""}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewPedidoHandler(&mockPedidoUseCases{
+				FilaCozinha:    []entity.Pedido{{Id: 2, Cpf: 12345, Status: entity.StatusPronto, MetodoPagamento: "card", PagamentoAprovado: true}},
+				FilaCozinhaErr: test.mockErr,
+			}, nil, "")
+
+			req := httptest.NewRequest("GET", "/pedido/fila", nil)
+			rec := httptest.NewRecorder()
+
+			handler.FilaCozinhaRoute(rec, req)
+
+			resp := rec.Result()
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+
+			if resp.StatusCode != test.expectedCode {
+				t.Errorf("Expected status code %d, got %d", test.expectedCode, resp.StatusCode)
+			}
+
+			if string(body) != test.expectedBody {
+				t.Errorf("Expected body %q, got %q", test.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestCancelarPedidoRoute(t *testing.T) {
+	cancelado := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		url          string
+		body         string
+		mockErr      error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Successful cancellation with refund",
+			url:          "/pedido/2/cancelamento",
+			body:         `{"motivo":"item em falta","cancelado_por":"cozinha"}`,
+			expectedCode: 200,
+			expectedBody: `{"pedido_id":2,"motivo":"item em falta","cancelado_por":"cozinha","cancelado_em":"2024-05-01T12:30:00Z","reembolso":{"valor":{"valor":25.00,"moeda":"BRL"},"status":"solicitado"}}`,
+		},
+		{
+			name:         "Invalid id",
+			url:          "/pedido/abc/cancelamento",
+			body:         `{"motivo":"item em falta","cancelado_por":"cozinha"}`,
+			expectedCode: 400,
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Id de pedido inválido","request_id":""}`,
+		},
+		{
+			name:         "Invalid JSON",
+			url:          "/pedido/2/cancelamento",
+			body:         `{invalid-json}`,
+			expectedCode: 400,
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Corpo da requisição inválido","request_id":""}`,
+		},
+		{
+			name:         "Missing reason",
+			url:          "/pedido/2/cancelamento",
+			body:         `{"cancelado_por":"cozinha"}`,
+			mockErr:      entity.ErrCancelamentoInvalido.ComCampos(entity.ErroCampo{Campo: "motivo", Mensagem: "obrigatório"}),
+			expectedCode: 422,
+			expectedBody: `{"status":422,"codigo":"cancelamento_invalido","mensagem":"Cancelamento inválido","campos":[{"campo":"motivo","mensagem":"obrigatório"}],"request_id":""}`,
+		},
+		{
+			name:         "Order already ready",
+			url:          "/pedido/2/cancelamento",
+			body:         `{"motivo":"demorou","cancelado_por":"cliente"}`,
+			mockErr:      fmt.Errorf("%w: pedido está %q", entity.ErrCancelamentoNaoPermitido, entity.StatusPronto),
+			expectedCode: 409,
+			expectedBody: `{"status":409,"codigo":"cancelamento_nao_permitido","mensagem":"Pedido não pode mais ser cancelado: pedido está \"Pronto\"","request_id":""}`,
+		},
+		{
+			name:         "Unknown order",
+			url:          "/pedido/99/cancelamento",
+			body:         `{"motivo":"demorou","cancelado_por":"cliente"}`,
+			mockErr:      entity.ErrPedidoNaoEncontrado,
+			expectedCode: 404,
+			expectedBody: `{"status":404,"codigo":"pedido_nao_encontrado","mensagem":"Pedido não encontrado","request_id":""}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockUsecase := &mockPedidoUseCases{
+				Cancelamento: entity.Cancelamento{CanceladoEm: cancelado, Reembolso: &entity.Reembolso{Valor: entity.Centavos(2500), Status: entity.ReembolsoSolicitado}},
+				CancelarErr:  test.mockErr,
+			}
+
+			rec := httptest.NewRecorder()
+			NovoRoteador(NewPedidoHandler(mockUsecase, nil, "")).ServeHTTP(rec, httptest.NewRequest("POST", test.url, strings.NewReader(test.body)))
+
+			if rec.Code != test.expectedCode {
+				t.Errorf("Expected status code %d, got %d", test.expectedCode, rec.Code)
+			}
+
+			if rec.Body.String() != test.expectedBody {
+				t.Errorf("Expected body %q, got %q", test.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHistoricoPedidoRoute(t *testing.T) {
+	criado := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	historico := []entity.HistoricoPedido{
+		{Evento: entity.EventoCriacao, Status: entity.StatusRecebido, Responsavel: entity.ResponsavelCliente, RegistradoEm: criado},
+		{Evento: entity.EventoPagamento, Status: entity.StatusRecebido, PagamentoAprovado: true, Responsavel: entity.ResponsavelPagamento, RegistradoEm: criado.Add(time.Minute)},
+		{Evento: entity.EventoCancelamento, Status: entity.StatusCancelado, PagamentoAprovado: true, Responsavel: "cozinha", Motivo: "item em falta", RegistradoEm: criado.Add(5 * time.Minute)},
+	}
+
+	tests := []struct {
+		name         string
+		url          string
+		mockErr      error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Successful GET",
+			url:          "/pedido/2/historico",
+			expectedCode: 200,
+			expectedBody: `[{"evento":"criacao","status":"Recebido","pagamento_aprovado":false,"responsavel":"cliente","registrado_em":"2024-05-01T12:00:00Z"},{"evento":"pagamento","status":"Recebido","pagamento_aprovado":true,"responsavel":"servico-pagamento","registrado_em":"2024-05-01T12:01:00Z"},{"evento":"cancelamento","status":"Cancelado","pagamento_aprovado":true,"responsavel":"cozinha","motivo":"item em falta","registrado_em":"2024-05-01T12:05:00Z"}]`,
+		},
+		{
+			name:         "Invalid id",
+			url:          "/pedido/abc/historico",
+			expectedCode: 400,
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Id de pedido inválido","request_id":""}`,
+		},
+		{
+			name:         "Unknown order",
+			url:          "/pedido/99/historico",
+			mockErr:      entity.ErrPedidoNaoEncontrado,
+			expectedCode: 404,
+			expectedBody: `{"status":404,"codigo":"pedido_nao_encontrado","mensagem":"Pedido não encontrado","request_id":""}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockUsecase := &mockPedidoUseCases{Historico: historico, HistoricoErr: test.mockErr}
+
+			rec := httptest.NewRecorder()
+			NovoRoteador(NewPedidoHandler(mockUsecase, nil, "")).ServeHTTP(rec, httptest.NewRequest("GET", test.url, nil))
+
+			if rec.Code != test.expectedCode {
+				t.Errorf("Expected status code %d, got %d", test.expectedCode, rec.Code)
+			}
+
+			if rec.Body.String() != test.expectedBody {
+				t.Errorf("Expected body %q, got %q", test.expectedBody, rec.Body.String())
+			}
+		})
+	}
 }
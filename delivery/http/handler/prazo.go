@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ComPrazo limita o tempo de processamento de cada requisição. O contexto da requisição é
+// cancelado quando o prazo expira ou quando o cliente desconecta, interrompendo as consultas
+// e chamadas externas em andamento. NovoRoteadorComPrazo aplica o prazo rota a rota,
+// deixando de fora os fluxos de eventos.
+func ComPrazo(prazo time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), prazo)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestComPrazo(t *testing.T) {
+	t.Run("Sets a deadline on the request context", func(t *testing.T) {
+		var prazo time.Time
+		var definido bool
+		handler := ComPrazo(time.Second, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			prazo, definido = r.Context().Deadline()
+		}))
+
+		inicio := time.Now()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pedido", nil))
+
+		if !definido {
+			t.Fatal("expected request context to have a deadline")
+		}
+		if prazo.Before(inicio) || prazo.After(inicio.Add(time.Second+100*time.Millisecond)) {
+			t.Errorf("unexpected deadline %v", prazo)
+		}
+	})
+
+	t.Run("Cancels the context when the deadline expires", func(t *testing.T) {
+		var err error
+		handler := ComPrazo(10*time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			err = r.Context().Err()
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/pedido", nil))
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("Accept header does not lift the deadline", func(t *testing.T) {
+		var definido bool
+		handler := ComPrazo(time.Second, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, definido = r.Context().Deadline()
+		}))
+
+		req := httptest.NewRequest("GET", "/pedido", nil)
+		req.Header.Set("Accept", "text/event-stream")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if !definido {
+			t.Error("expected a deadline regardless of the Accept header")
+		}
+	})
+}
+
+// rotasFake registra as rotas informadas com um handler que anota se a requisição tinha prazo.
+type rotasFake struct {
+	patterns []string
+	prazos   map[string]bool
+}
+
+func (f *rotasFake) Registrar(mux Mux) {
+	for _, p := range f.patterns {
+		mux.HandleFunc(p, func(w http.ResponseWriter, r *http.Request) {
+			_, f.prazos[p] = r.Context().Deadline()
+		})
+	}
+}
+
+type muxGravado []string
+
+func (m *muxGravado) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	*m = append(*m, pattern)
+}
+
+func TestRoteadorComPrazo(t *testing.T) {
+	rotas := &rotasFake{patterns: []string{"GET /pedido", "GET /pedido/eventos", "GET /pedido/{id}/eventos"}, prazos: map[string]bool{}}
+	roteador := NovoRoteadorComPrazo(time.Second, rotas)
+
+	for _, url := range []string{"/pedido", "/pedido/eventos", "/pedido/1/eventos"} {
+		roteador.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", url, nil))
+	}
+
+	esperado := map[string]bool{"GET /pedido": true, "GET /pedido/eventos": false, "GET /pedido/{id}/eventos": false}
+	for pattern, prazo := range esperado {
+		if rotas.prazos[pattern] != prazo {
+			t.Errorf("%s: expected deadline %v, got %v", pattern, prazo, rotas.prazos[pattern])
+		}
+	}
+
+	t.Run("Exempt routes are the event streams", func(t *testing.T) {
+		var registrados muxGravado
+		NewEventosPedidoHandler(&mockPedidoUseCases{}, novaFonte(false)).Registrar(&registrados)
+		if len(registrados) != len(rotasSemPrazo) {
+			t.Fatalf("expected %d event routes, got %v", len(rotasSemPrazo), registrados)
+		}
+		for _, pattern := range registrados {
+			if !rotasSemPrazo[pattern] {
+				t.Errorf("expected %q to be registered without deadline", pattern)
+			}
+		}
+	})
+}
@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gomesmatheus/tc-pedido/domain/entity"
 	"github.com/gomesmatheus/tc-pedido/usecase"
@@ -22,26 +21,33 @@ func NewProdutoHandler(produtoUseCases usecase.ProdutoUseCases) *ProdutoHandler
 	}
 }
 
+func (c *ProdutoHandler) Registrar(mux Mux) {
+	mux.HandleFunc("POST /produto", c.CriacaoProdutoRoute)
+	mux.HandleFunc("GET /produto", c.RecuperarProdutosRoute)
+	mux.HandleFunc("GET /produto/{id}", c.ProdutoPorIdRoute)
+	mux.HandleFunc("PUT /produto/{id}", c.ProdutoPorIdRoute)
+	mux.HandleFunc("DELETE /produto/{id}", c.ProdutoPorIdRoute)
+}
+
 func (c *ProdutoHandler) CriacaoProdutoRoute(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "POST" {
 		body, err := io.ReadAll(r.Body)
 		defer r.Body.Close()
 		if err != nil {
-			fmt.Println("Error parsing request body")
-			w.WriteHeader(400)
-			w.Write([]byte("400 bad request"))
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
 			return
 		}
 
 		var produto entity.Produto
-		json.Unmarshal(body, &produto)
+		if err := json.Unmarshal(body, &produto); err != nil {
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
+			return
+		}
 		fmt.Println(produto)
 
-		produto, err = c.produtoUseCases.CriarProduto(produto)
+		produto, err = c.produtoUseCases.CriarProduto(r.Context(), produto)
 		if err != nil {
-			fmt.Println("Erro ao cadastrar o produto", err)
-			w.WriteHeader(500)
-			w.Write([]byte("Erro ao cadastrar o produto"))
+			escreverErro(w, r, err)
 			return
 		}
 
@@ -52,24 +58,49 @@ func (c *ProdutoHandler) CriacaoProdutoRoute(w http.ResponseWriter, r *http.Requ
 	return
 }
 
+// RecuperarProdutosRoute lista o catálogo inteiro ou, com categoria_id, apenas os produtos
+// de uma categoria.
 func (c *ProdutoHandler) RecuperarProdutosRoute(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("bateu na rota de recuperar produtos")
-	id, err := strconv.ParseInt(strings.Split(r.URL.Path, "/")[2], 10, 64)
-
-	if r.Method == "GET" {
-		categoriaId := id
-		if err != nil {
-			fmt.Println(err)
+	var produtos []entity.Produto
+	var err error
+	if v := r.URL.Query().Get("categoria_id"); v != "" {
+		categoriaId, convErr := strconv.ParseInt(v, 10, 64)
+		if convErr != nil {
+			requisicaoInvalida(w, r, "Id de categoria inválido")
+			return
 		}
+		produtos, err = c.produtoUseCases.RecuperarProdutos(r.Context(), int(categoriaId))
+	} else {
+		produtos, err = c.produtoUseCases.RecuperarTodosProdutos(r.Context())
+	}
+	if err != nil {
+		escreverErro(w, r, err)
+		return
+	}
+	if produtos == nil {
+		produtos = []entity.Produto{}
+	}
 
-		produtos, err := c.produtoUseCases.RecuperarProdutos(int(categoriaId))
+	response, _ := json.Marshal(produtos)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(response)
+}
+
+func (c *ProdutoHandler) ProdutoPorIdRoute(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		requisicaoInvalida(w, r, "Id inválido")
+		return
+	}
+
+	if r.Method == "GET" {
+		produto, err := c.produtoUseCases.RecuperarProduto(r.Context(), int(id))
 		if err != nil {
-			w.WriteHeader(404)
-			fmt.Println(err)
-			w.Write([]byte(fmt.Sprintf("Erro ao recuperar produtos com categoria_id %d", categoriaId)))
+			escreverErro(w, r, err)
 			return
 		}
-		response, _ := json.Marshal(produtos)
+		response, _ := json.Marshal(produto)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(200)
 		w.Write(response)
@@ -77,28 +108,25 @@ func (c *ProdutoHandler) RecuperarProdutosRoute(w http.ResponseWriter, r *http.R
 		body, err := io.ReadAll(r.Body)
 		defer r.Body.Close()
 		if err != nil {
-			fmt.Println("Error parsing request body")
-			w.WriteHeader(400)
-			w.Write([]byte("400 bad request"))
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
 			return
 		}
 
 		var produto entity.Produto
-		json.Unmarshal(body, &produto)
-		err = c.produtoUseCases.AtualizarProduto(int(id), produto)
+		if err := json.Unmarshal(body, &produto); err != nil {
+			requisicaoInvalida(w, r, "Corpo da requisição inválido")
+			return
+		}
+		err = c.produtoUseCases.AtualizarProduto(r.Context(), int(id), produto)
 		if err != nil {
-			fmt.Println("Erro ao atualizar produto")
-			w.WriteHeader(500)
-			w.Write([]byte("500 Erro ao atualizar produto"))
+			escreverErro(w, r, err)
 			return
 		}
 
 	} else if r.Method == "DELETE" {
-		err := c.produtoUseCases.DeletarProduto(int(id))
+		err := c.produtoUseCases.DeletarProduto(r.Context(), int(id))
 		if err != nil {
-			fmt.Println("Erro ao deletar produto")
-			w.WriteHeader(500)
-			w.Write([]byte("500 Erro ao deletar produto"))
+			escreverErro(w, r, err)
 			return
 		}
 	}
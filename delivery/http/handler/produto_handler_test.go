@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -15,23 +17,33 @@ import (
 type MockProdutoUseCases struct {
 	CriarProdutoFn      func(produto entity.Produto) (entity.Produto, error)
 	RecuperarProdutosFn func(categoriaId int) ([]entity.Produto, error)
+	RecuperarTodosFn    func() ([]entity.Produto, error)
+	RecuperarProdutoFn  func(id int) (entity.Produto, error)
 	AtualizarProdutoFn  func(id int, produto entity.Produto) error
 	DeletarProdutoFn    func(id int) error
 }
 
-func (m *MockProdutoUseCases) CriarProduto(produto entity.Produto) (entity.Produto, error) {
+func (m *MockProdutoUseCases) CriarProduto(ctx context.Context, produto entity.Produto) (entity.Produto, error) {
 	return m.CriarProdutoFn(produto)
 }
 
-func (m *MockProdutoUseCases) RecuperarProdutos(categoriaId int) ([]entity.Produto, error) {
+func (m *MockProdutoUseCases) RecuperarProdutos(ctx context.Context, categoriaId int) ([]entity.Produto, error) {
 	return m.RecuperarProdutosFn(categoriaId)
 }
 
-func (m *MockProdutoUseCases) AtualizarProduto(id int, produto entity.Produto) error {
+func (m *MockProdutoUseCases) RecuperarTodosProdutos(ctx context.Context) ([]entity.Produto, error) {
+	return m.RecuperarTodosFn()
+}
+
+func (m *MockProdutoUseCases) RecuperarProduto(ctx context.Context, id int) (entity.Produto, error) {
+	return m.RecuperarProdutoFn(id)
+}
+
+func (m *MockProdutoUseCases) AtualizarProduto(ctx context.Context, id int, produto entity.Produto) error {
 	return m.AtualizarProdutoFn(id, produto)
 }
 
-func (m *MockProdutoUseCases) DeletarProduto(id int) error {
+func (m *MockProdutoUseCases) DeletarProduto(ctx context.Context, id int) error {
 	return m.DeletarProdutoFn(id)
 }
 
@@ -56,11 +68,75 @@ func TestCriacaoProdutoRoute(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:         "Price as decimal string",
+			body:         `{"nome":"Produto Teste","preco":"29.99"}`,
+			expectedCode: http.StatusCreated,
+			expectedBody: "Produto inserido",
+			mockResponse: func() *MockProdutoUseCases {
+				return &MockProdutoUseCases{
+					CriarProdutoFn: func(produto entity.Produto) (entity.Produto, error) {
+						if produto.Preco != entity.Centavos(2999) {
+							return produto, fmt.Errorf("unexpected price %s", produto.Preco)
+						}
+						return produto, nil
+					},
+				}
+			},
+		},
+		{
+			name:         "Price as money object",
+			body:         `{"nome":"Produto Teste","preco":{"valor":29.99,"moeda":"BRL"}}`,
+			expectedCode: http.StatusCreated,
+			expectedBody: "Produto inserido",
+			mockResponse: func() *MockProdutoUseCases {
+				return &MockProdutoUseCases{
+					CriarProdutoFn: func(produto entity.Produto) (entity.Produto, error) {
+						if produto.Preco != entity.Centavos(2999) {
+							return produto, fmt.Errorf("unexpected price %s", produto.Preco)
+						}
+						return produto, nil
+					},
+				}
+			},
+		},
+		{
+			name:         "Price in another currency",
+			body:         `{"nome":"Produto Teste","preco":{"valor":29.99,"moeda":"USD"}}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Corpo da requisição inválido","request_id":""}`,
+			mockResponse: func() *MockProdutoUseCases {
+				return &MockProdutoUseCases{}
+			},
+		},
+		{
+			name:         "Price with fractions of a cent",
+			body:         `{"nome":"Produto Teste","preco":29.999}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Corpo da requisição inválido","request_id":""}`,
+			mockResponse: func() *MockProdutoUseCases {
+				return &MockProdutoUseCases{}
+			},
+		},
+		{
+			name:         "Unknown categoria",
+			body:         `{"nome":"Produto Teste","categoria_id":9}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `{"status":422,"codigo":"produto_invalido","mensagem":"Produto inválido: Categoria não encontrada","campos":[{"campo":"categoria_id","mensagem":"categoria 9 não encontrada"}],"request_id":""}`,
+			mockResponse: func() *MockProdutoUseCases {
+				return &MockProdutoUseCases{
+					CriarProdutoFn: func(produto entity.Produto) (entity.Produto, error) {
+						campo := entity.ErroCampo{Campo: "categoria_id", Mensagem: fmt.Sprintf("categoria %d não encontrada", produto.CategoriaId)}
+						return produto, fmt.Errorf("%w: %w", entity.ErrProdutoInvalido.ComCampos(campo), entity.ErrCategoriaNaoEncontrada)
+					},
+				}
+			},
+		},
 		{
 			name:         "Error creating product",
 			body:         `{"nome":"Produto Teste"}`,
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: "Erro ao cadastrar o produto",
+			expectedBody: `{"status":500,"codigo":"erro_interno","mensagem":"Erro interno ao processar a requisição","request_id":""}`,
 			mockResponse: func() *MockProdutoUseCases {
 				return &MockProdutoUseCases{
 					CriarProdutoFn: func(produto entity.Produto) (entity.Produto, error) {
@@ -106,23 +182,58 @@ func TestRecuperarProdutosRoute(t *testing.T) {
 		mockResponse func() *MockProdutoUseCases
 	}{
 		{
-			name:         "Successful retrieval",
-			url:          "/produtos/1",
+			name:         "Successful retrieval by categoria",
+			url:          "/produto?categoria_id=1",
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"id":1,"categoria_id":1,"nome":"Produto Teste","descricao":"","preco":{"valor":0.00,"moeda":"BRL"},"tempo_de_preparo":0}]`,
+			mockResponse: func() *MockProdutoUseCases {
+				return &MockProdutoUseCases{
+					RecuperarProdutosFn: func(categoriaId int) ([]entity.Produto, error) {
+						return []entity.Produto{{Id: 1, CategoriaId: categoriaId, Nome: "Produto Teste"}}, nil
+					},
+				}
+			},
+		},
+		{
+			name:         "Successful retrieval of the whole catalogue",
+			url:          "/produto",
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"id":1,"categoria_id":1,"nome":"Lanche","descricao":"","preco":{"valor":0.00,"moeda":"BRL"},"tempo_de_preparo":0},{"id":2,"categoria_id":3,"nome":"Bebida","descricao":"","preco":{"valor":0.00,"moeda":"BRL"},"tempo_de_preparo":0}]`,
+			mockResponse: func() *MockProdutoUseCases {
+				return &MockProdutoUseCases{
+					RecuperarTodosFn: func() ([]entity.Produto, error) {
+						return []entity.Produto{{Id: 1, CategoriaId: 1, Nome: "Lanche"}, {Id: 2, CategoriaId: 3, Nome: "Bebida"}}, nil
+					},
+				}
+			},
+		},
+		{
+			name:         "Empty categoria",
+			url:          "/produto?categoria_id=4",
 			expectedCode: http.StatusOK,
-			expectedBody: `[{"id":1,"categoria_id":0,"nome":"Produto Teste","descricao":"","preco":0,"tempo_de_preparo":0}]`,
+			expectedBody: `[]`,
 			mockResponse: func() *MockProdutoUseCases {
 				return &MockProdutoUseCases{
 					RecuperarProdutosFn: func(categoriaId int) ([]entity.Produto, error) {
-						return []entity.Produto{{Id: 1, Nome: "Produto Teste"}}, nil
+						return nil, nil
 					},
 				}
 			},
 		},
+		{
+			name:         "Invalid categoria_id",
+			url:          "/produto?categoria_id=abc",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Id de categoria inválido","request_id":""}`,
+			mockResponse: func() *MockProdutoUseCases {
+				return &MockProdutoUseCases{}
+			},
+		},
 		{
 			name:         "Error retrieving products",
-			url:          "/produtos/1",
-			expectedCode: http.StatusNotFound,
-			expectedBody: "Erro ao recuperar produtos com categoria_id 1",
+			url:          "/produto?categoria_id=1",
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"status":500,"codigo":"erro_interno","mensagem":"Erro interno ao processar a requisição","request_id":""}`,
 			mockResponse: func() *MockProdutoUseCases {
 				return &MockProdutoUseCases{
 					RecuperarProdutosFn: func(categoriaId int) ([]entity.Produto, error) {
@@ -141,7 +252,7 @@ func TestRecuperarProdutosRoute(t *testing.T) {
 			req := httptest.NewRequest("GET", test.url, nil)
 			rr := httptest.NewRecorder()
 
-			handler.RecuperarProdutosRoute(rr, req)
+			NovoRoteador(handler).ServeHTTP(rr, req)
 
 			resp := rr.Result()
 			defer resp.Body.Close()
@@ -158,6 +269,56 @@ func TestRecuperarProdutosRoute(t *testing.T) {
 	}
 }
 
+func TestRecuperarProdutoRoute(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		expectedCode int
+		expectedBody string
+		mockErr      error
+	}{
+		{
+			name:         "Successful retrieval",
+			url:          "/produto/7",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"id":7,"categoria_id":1,"nome":"X-Burger","descricao":"","preco":{"valor":25.50,"moeda":"BRL"},"tempo_de_preparo":10}`,
+		},
+		{
+			name:         "Unknown produto",
+			url:          "/produto/99",
+			mockErr:      entity.ErrProdutoNaoEncontrado,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"status":404,"codigo":"produto_nao_encontrado","mensagem":"Produto não encontrado","request_id":""}`,
+		},
+		{
+			name:         "Invalid id",
+			url:          "/produto/abc",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"status":400,"codigo":"requisicao_invalida","mensagem":"Id inválido","request_id":""}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewProdutoHandler(&MockProdutoUseCases{
+				RecuperarProdutoFn: func(id int) (entity.Produto, error) {
+					return entity.Produto{Id: id, CategoriaId: 1, Nome: "X-Burger", Preco: entity.Centavos(2550), TempoDePreparo: 10}, test.mockErr
+				},
+			})
+
+			rr := httptest.NewRecorder()
+			NovoRoteador(handler).ServeHTTP(rr, httptest.NewRequest("GET", test.url, nil))
+
+			if rr.Code != test.expectedCode {
+				t.Errorf("expected status %d, got %d", test.expectedCode, rr.Code)
+			}
+			if rr.Body.String() != test.expectedBody {
+				t.Errorf("expected body %q, got %q", test.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
 func TestAtualizarProdutoRoute(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -169,7 +330,7 @@ func TestAtualizarProdutoRoute(t *testing.T) {
 	}{
 		{
 			name:         "Successful update",
-			url:          "/produtos/1",
+			url:          "/produto/1",
 			body:         `{"nome":"Produto Atualizado"}`,
 			expectedCode: http.StatusOK,
 			expectedBody: "",
@@ -183,10 +344,10 @@ func TestAtualizarProdutoRoute(t *testing.T) {
 		},
 		{
 			name:         "Error updating product",
-			url:          "/produtos/1",
+			url:          "/produto/1",
 			body:         `{"nome":"Produto Atualizado"}`,
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: "500 Erro ao atualizar produto",
+			expectedBody: `{"status":500,"codigo":"erro_interno","mensagem":"Erro interno ao processar a requisição","request_id":""}`,
 			mockResponse: func() *MockProdutoUseCases {
 				return &MockProdutoUseCases{
 					AtualizarProdutoFn: func(id int, produto entity.Produto) error {
@@ -206,7 +367,7 @@ func TestAtualizarProdutoRoute(t *testing.T) {
 			req.Header.Set("Content-Type", "application/json")
 			rr := httptest.NewRecorder()
 
-			handler.RecuperarProdutosRoute(rr, req)
+			NovoRoteador(handler).ServeHTTP(rr, req)
 
 			resp := rr.Result()
 			defer resp.Body.Close()
@@ -233,7 +394,7 @@ func TestDeletarProdutoRoute(t *testing.T) {
 	}{
 		{
 			name:         "Successful deletion",
-			url:          "/produtos/1",
+			url:          "/produto/1",
 			expectedCode: http.StatusOK,
 			expectedBody: "",
 			mockResponse: func() *MockProdutoUseCases {
@@ -246,9 +407,9 @@ func TestDeletarProdutoRoute(t *testing.T) {
 		},
 		{
 			name:         "Error deleting product",
-			url:          "/produtos/1",
+			url:          "/produto/1",
 			expectedCode: http.StatusInternalServerError,
-			expectedBody: "500 Erro ao deletar produto",
+			expectedBody: `{"status":500,"codigo":"erro_interno","mensagem":"Erro interno ao processar a requisição","request_id":""}`,
 			mockResponse: func() *MockProdutoUseCases {
 				return &MockProdutoUseCases{
 					DeletarProdutoFn: func(id int) error {
@@ -267,7 +428,7 @@ func TestDeletarProdutoRoute(t *testing.T) {
 			req := httptest.NewRequest("DELETE", test.url, nil)
 			rr := httptest.NewRecorder()
 
-			handler.RecuperarProdutosRoute(rr, req)
+			NovoRoteador(handler).ServeHTTP(rr, req)
 
 			resp := rr.Result()
 			defer resp.Body.Close()
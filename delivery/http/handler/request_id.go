@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const HeaderRequestId = "X-Request-Id"
+
+type chaveRequestId struct{}
+
+// ComRequestId identifica cada requisição, reaproveitando o X-Request-Id recebido do
+// cliente ou do proxy quando presente, e devolve o identificador no cabeçalho da resposta.
+func ComRequestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HeaderRequestId)
+		if id == "" || len(id) > 128 {
+			id = novoRequestId()
+		}
+
+		w.Header().Set(HeaderRequestId, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), chaveRequestId{}, id)))
+	})
+}
+
+func RequestId(ctx context.Context) string {
+	id, _ := ctx.Value(chaveRequestId{}).(string)
+	return id
+}
+
+func novoRequestId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Mux é a parte do http.ServeMux usada pelos handlers para registrar suas rotas.
+type Mux interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
+// Rotas é implementado pelos handlers que registram as próprias rotas no roteador.
+type Rotas interface {
+	Registrar(mux Mux)
+}
+
+// aliasLegado reescreve um caminho antigo, anterior às rotas com parâmetros, para o
+// caminho atual equivalente.
+type aliasLegado struct {
+	prefixo    string
+	reescrever func(id string) string
+}
+
+var aliasesLegados = []aliasLegado{
+	{prefixo: "/pedido/atualizar/", reescrever: func(id string) string { return "/pedido/" + id + "/status" }},
+}
+
+// rotasSemPrazo são os fluxos de eventos, que ficam abertos enquanto o cliente estiver
+// conectado e por isso não recebem o prazo de processamento.
+var rotasSemPrazo = map[string]bool{
+	"GET /pedido/eventos":      true,
+	"GET /pedido/{id}/eventos": true,
+}
+
+type Roteador struct {
+	mux *http.ServeMux
+}
+
+func NovoRoteador(rotas ...Rotas) *Roteador {
+	return NovoRoteadorComPrazo(0, rotas...)
+}
+
+// NovoRoteadorComPrazo aplica ComPrazo a cada rota registrada, exceto às de rotasSemPrazo.
+// Um prazo zero não limita nenhuma rota.
+func NovoRoteadorComPrazo(prazo time.Duration, rotas ...Rotas) *Roteador {
+	mux := http.NewServeMux()
+	var registro Mux = mux
+	if prazo > 0 {
+		registro = muxComPrazo{mux: mux, prazo: prazo}
+	}
+	for _, r := range rotas {
+		r.Registrar(registro)
+	}
+
+	return &Roteador{mux: mux}
+}
+
+type muxComPrazo struct {
+	mux   *http.ServeMux
+	prazo time.Duration
+}
+
+func (m muxComPrazo) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	if rotasSemPrazo[pattern] {
+		m.mux.HandleFunc(pattern, handler)
+		return
+	}
+	m.mux.Handle(pattern, ComPrazo(m.prazo, http.HandlerFunc(handler)))
+}
+
+// ServeHTTP despacha a requisição para a rota registrada. Caminhos inexistentes e métodos
+// não suportados são respondidos com 404 e 405 no formato de Problema, mantendo o
+// cabeçalho Allow calculado pelo ServeMux.
+func (rt *Roteador) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r = reescreverAlias(r)
+
+	if _, pattern := rt.mux.Handler(r); pattern != "" {
+		rt.mux.ServeHTTP(w, r)
+		return
+	}
+
+	resposta := &respostaDescartada{header: http.Header{}}
+	rt.mux.ServeHTTP(resposta, r)
+
+	if resposta.status == http.StatusMethodNotAllowed {
+		w.Header().Set("Allow", resposta.header.Get("Allow"))
+		escreverProblema(w, r, Problema{
+			Status:   http.StatusMethodNotAllowed,
+			Codigo:   "metodo_nao_permitido",
+			Mensagem: "Método " + r.Method + " não permitido para " + r.URL.Path,
+		})
+		return
+	}
+
+	escreverProblema(w, r, Problema{
+		Status:   http.StatusNotFound,
+		Codigo:   "rota_nao_encontrada",
+		Mensagem: "Rota " + r.URL.Path + " não encontrada",
+	})
+}
+
+func reescreverAlias(r *http.Request) *http.Request {
+	for _, alias := range aliasesLegados {
+		id, ok := strings.CutPrefix(r.URL.Path, alias.prefixo)
+		if !ok || id == "" || strings.Contains(id, "/") {
+			continue
+		}
+
+		r = r.Clone(r.Context())
+		r.URL.Path = alias.reescrever(id)
+		r.URL.RawPath = ""
+		return r
+	}
+
+	return r
+}
+
+// respostaDescartada captura apenas o status e os cabeçalhos que o ServeMux escreve para
+// rotas inexistentes, para que a resposta final siga o formato de erro da API.
+type respostaDescartada struct {
+	header http.Header
+	status int
+}
+
+func (r *respostaDescartada) Header() http.Header {
+	return r.header
+}
+
+func (r *respostaDescartada) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (r *respostaDescartada) WriteHeader(status int) {
+	r.status = status
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRoteador(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		body          string
+		expectedCode  int
+		expectedAllow string
+		expectedBody  string
+	}{
+		{
+			name:         "Status route with path parameter",
+			method:       "PATCH",
+			url:          "/pedido/1/status",
+			body:         `{"status":"Pronto"}`,
+			expectedCode: http.StatusCreated,
+			expectedBody: "Pedido atualizado",
+		},
+		{
+			name:         "Legacy status alias",
+			method:       "PATCH",
+			url:          "/pedido/atualizar/1",
+			body:         `{"status":"Pronto"}`,
+			expectedCode: http.StatusCreated,
+			expectedBody: "Pedido atualizado",
+		},
+		{
+			name:         "Legacy status alias without id",
+			method:       "PATCH",
+			url:          "/pedido/atualizar/",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"status":404,"codigo":"rota_nao_encontrada","mensagem":"Rota /pedido/atualizar/ não encontrada","request_id":""}`,
+		},
+		{
+			name:         "Unknown route",
+			method:       "GET",
+			url:          "/cliente",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"status":404,"codigo":"rota_nao_encontrada","mensagem":"Rota /cliente não encontrada","request_id":""}`,
+		},
+		{
+			name:          "Unsupported method",
+			method:        "DELETE",
+			url:           "/pedido",
+			expectedCode:  http.StatusMethodNotAllowed,
+			expectedAllow: "GET, HEAD, POST",
+			expectedBody:  `{"status":405,"codigo":"metodo_nao_permitido","mensagem":"Método DELETE não permitido para /pedido","request_id":""}`,
+		},
+		{
+			name:          "Unsupported method on path parameter route",
+			method:        "GET",
+			url:           "/pedido/1/status",
+			expectedCode:  http.StatusMethodNotAllowed,
+			expectedAllow: "PATCH",
+			expectedBody:  `{"status":405,"codigo":"metodo_nao_permitido","mensagem":"Método GET não permitido para /pedido/1/status","request_id":""}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			roteador := NovoRoteador(NewPedidoHandler(&mockPedidoUseCases{}, nil, ""))
+
+			req := httptest.NewRequest(test.method, test.url, bytes.NewBuffer([]byte(test.body)))
+			rr := httptest.NewRecorder()
+
+			roteador.ServeHTTP(rr, req)
+
+			resp := rr.Result()
+			defer resp.Body.Close()
+
+			if resp.StatusCode != test.expectedCode {
+				t.Errorf("expected status %d, got %d", test.expectedCode, resp.StatusCode)
+			}
+			if allow := resp.Header.Get("Allow"); allow != test.expectedAllow {
+				t.Errorf("expected Allow %q, got %q", test.expectedAllow, allow)
+			}
+
+			responseBody, _ := ioutil.ReadAll(resp.Body)
+			if string(responseBody) != test.expectedBody {
+				t.Errorf("expected body %q, got %q", test.expectedBody, responseBody)
+			}
+		})
+	}
+}
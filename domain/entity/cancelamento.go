@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+type StatusReembolso string
+
+const (
+	ReembolsoPendente   StatusReembolso = "pendente"
+	ReembolsoSolicitado StatusReembolso = "solicitado"
+
+	TamanhoMaximoMotivoCancelamento = 500
+	TamanhoMaximoCanceladoPor       = 100
+)
+
+var (
+	ErrCancelamentoInvalido     = NovoErro(ErroValidacao, "cancelamento_invalido", "Cancelamento inválido")
+	ErrCancelamentoNaoPermitido = NovoErro(ErroConflito, "cancelamento_nao_permitido", "Pedido não pode mais ser cancelado")
+	ErrPagamentoIndisponivel    = NovoErro(ErroIndisponivel, "pagamento_indisponivel", "Serviço de pagamentos indisponível")
+)
+
+// Cancelamento registra quem cancelou o pedido e por quê. Reembolso só é preenchido quando
+// o pagamento do pedido já tinha sido aprovado.
+type Cancelamento struct {
+	PedidoId     int        `json:"pedido_id"`
+	Motivo       string     `json:"motivo"`
+	CanceladoPor string     `json:"cancelado_por"`
+	CanceladoEm  time.Time  `json:"cancelado_em"`
+	Reembolso    *Reembolso `json:"reembolso"`
+}
+
+// Reembolso fica pendente até o serviço de pagamentos aceitar a solicitação.
+type Reembolso struct {
+	Valor  Dinheiro        `json:"valor"`
+	Status StatusReembolso `json:"status"`
+}
+
+// SolicitacaoReembolso é enviada ao serviço de pagamentos para devolver o valor do pedido.
+type SolicitacaoReembolso struct {
+	PedidoId int      `json:"pedido_id"`
+	Valor    Dinheiro `json:"valor"`
+	Motivo   string   `json:"motivo"`
+}
+
+// Validar lista os campos obrigatórios ausentes ou longos demais.
+func (c Cancelamento) Validar() []ErroCampo {
+	var campos []ErroCampo
+	if c.Motivo == "" {
+		campos = append(campos, ErroCampo{Campo: "motivo", Mensagem: "obrigatório"})
+	} else if utf8.RuneCountInString(c.Motivo) > TamanhoMaximoMotivoCancelamento {
+		campos = append(campos, ErroCampo{Campo: "motivo", Mensagem: fmt.Sprintf("deve ter no máximo %d caracteres", TamanhoMaximoMotivoCancelamento)})
+	}
+	if c.CanceladoPor == "" {
+		campos = append(campos, ErroCampo{Campo: "cancelado_por", Mensagem: "obrigatório"})
+	} else if utf8.RuneCountInString(c.CanceladoPor) > TamanhoMaximoCanceladoPor {
+		campos = append(campos, ErroCampo{Campo: "cancelado_por", Mensagem: fmt.Sprintf("deve ter no máximo %d caracteres", TamanhoMaximoCanceladoPor)})
+	}
+	return campos
+}
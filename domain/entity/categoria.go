@@ -0,0 +1,13 @@
+package entity
+
+var (
+	ErrCategoriaNaoEncontrada = NovoErro(ErroNaoEncontrado, "categoria_nao_encontrada", "Categoria não encontrada")
+	ErrCategoriaInvalida      = NovoErro(ErroValidacao, "categoria_invalida", "Categoria inválida")
+	ErrCategoriaEmUso         = NovoErro(ErroConflito, "categoria_em_uso", "Categoria possui produtos cadastrados")
+	ErrCategoriaDuplicada     = NovoErro(ErroConflito, "categoria_duplicada", "Já existe uma categoria com esta descrição")
+)
+
+type Categoria struct {
+	Id        int    `json:"id"`
+	Descricao string `json:"descricao"`
+}
@@ -0,0 +1,147 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Moeda string
+
+// Real é a única moeda do serviço; a base guarda apenas os centavos.
+const Real Moeda = "BRL"
+
+var (
+	ErrValorMonetarioInvalido = errors.New("valor monetário inválido")
+	ErrMoedasDiferentes       = errors.New("valores em moedas diferentes")
+)
+
+// Dinheiro guarda valores em centavos inteiros para que preços e totais não acumulem
+// erro de arredondamento. Em JSON é escrito como {"valor": 29.99, "moeda": "BRL"}; na
+// entrada também é aceito só o valor, como número ou string, que fica em Real.
+type Dinheiro struct {
+	Centavos int64
+	Moeda    Moeda
+}
+
+func Centavos(centavos int64) Dinheiro {
+	return Dinheiro{Centavos: centavos, Moeda: Real}
+}
+
+// ParseDinheiro converte um valor decimal como "29.99" sem passar por ponto flutuante.
+func ParseDinheiro(valor string) (Dinheiro, error) {
+	texto := strings.TrimSpace(valor)
+	negativo := strings.HasPrefix(texto, "-")
+	texto = strings.TrimPrefix(texto, "-")
+
+	inteiro, fracao, _ := strings.Cut(texto, ".")
+	if inteiro == "" || len(fracao) > 2 || !apenasDigitos(inteiro) || !apenasDigitos(fracao) {
+		return Dinheiro{}, fmt.Errorf("%w: %q", ErrValorMonetarioInvalido, valor)
+	}
+	fracao += strings.Repeat("0", 2-len(fracao))
+
+	centavos, err := strconv.ParseInt(inteiro+fracao, 10, 64)
+	if err != nil {
+		return Dinheiro{}, fmt.Errorf("%w: %q", ErrValorMonetarioInvalido, valor)
+	}
+	if negativo {
+		centavos = -centavos
+	}
+	return Centavos(centavos), nil
+}
+
+func apenasDigitos(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// moeda trata o valor zero de Dinheiro como Real.
+func (d Dinheiro) moeda() Moeda {
+	if d.Moeda == "" {
+		return Real
+	}
+	return d.Moeda
+}
+
+// Somar devolve ErrMoedasDiferentes quando os valores não estão na mesma moeda.
+func (d Dinheiro) Somar(outro Dinheiro) (Dinheiro, error) {
+	if d.moeda() != outro.moeda() {
+		return Dinheiro{}, fmt.Errorf("%w: %s e %s", ErrMoedasDiferentes, d.moeda(), outro.moeda())
+	}
+	return Dinheiro{Centavos: d.Centavos + outro.Centavos, Moeda: d.moeda()}, nil
+}
+
+func (d Dinheiro) Multiplicar(quantidade int) Dinheiro {
+	return Dinheiro{Centavos: d.Centavos * int64(quantidade), Moeda: d.moeda()}
+}
+
+func (d Dinheiro) Zero() bool {
+	return d.Centavos == 0
+}
+
+func (d Dinheiro) Positivo() bool {
+	return d.Centavos > 0
+}
+
+func (d Dinheiro) String() string {
+	centavos := d.Centavos
+	sinal := ""
+	if centavos < 0 {
+		sinal = "-"
+		centavos = -centavos
+	}
+	return fmt.Sprintf("%s%d.%02d", sinal, centavos/100, centavos%100)
+}
+
+func (d Dinheiro) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"valor":%s,"moeda":%q}`, d.String(), d.moeda())), nil
+}
+
+// UnmarshalJSON aceita o objeto escrito por MarshalJSON ou só o valor. Moedas diferentes de
+// Real são rejeitadas, já que a base não guarda a moeda.
+func (d *Dinheiro) UnmarshalJSON(dados []byte) error {
+	dados = bytes.TrimSpace(dados)
+	if bytes.Equal(dados, []byte("null")) {
+		*d = Dinheiro{}
+		return nil
+	}
+
+	moeda := Real
+	if bytes.HasPrefix(dados, []byte("{")) {
+		var objeto struct {
+			Valor json.RawMessage `json:"valor"`
+			Moeda Moeda           `json:"moeda"`
+		}
+		if err := json.Unmarshal(dados, &objeto); err != nil {
+			return err
+		}
+		if objeto.Moeda != "" {
+			moeda = objeto.Moeda
+		}
+		dados = bytes.TrimSpace(objeto.Valor)
+	}
+	if moeda != Real {
+		return fmt.Errorf("%w: moeda %q não suportada", ErrValorMonetarioInvalido, moeda)
+	}
+
+	texto := string(dados)
+	if strings.HasPrefix(texto, `"`) {
+		if err := json.Unmarshal(dados, &texto); err != nil {
+			return err
+		}
+	}
+
+	valor, err := ParseDinheiro(texto)
+	if err != nil {
+		return err
+	}
+	*d = valor
+	return nil
+}
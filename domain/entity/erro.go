@@ -0,0 +1,48 @@
+package entity
+
+// TipoErro classifica os erros de domínio para que a camada de entrega escolha a resposta
+// adequada sem precisar conhecer cada erro individualmente.
+type TipoErro int
+
+const (
+	ErroInterno TipoErro = iota
+	ErroNaoEncontrado
+	ErroValidacao
+	ErroConflito
+	ErroIndisponivel
+	ErroNaoAutorizado
+)
+
+type ErroCampo struct {
+	Campo    string `json:"campo"`
+	Mensagem string `json:"mensagem"`
+}
+
+type ErroDominio struct {
+	Tipo     TipoErro
+	Codigo   string
+	Mensagem string
+	Campos   []ErroCampo
+}
+
+func NovoErro(tipo TipoErro, codigo, mensagem string) *ErroDominio {
+	return &ErroDominio{Tipo: tipo, Codigo: codigo, Mensagem: mensagem}
+}
+
+func (e *ErroDominio) Error() string {
+	return e.Mensagem
+}
+
+// Is compara os erros pelo código, de modo que uma cópia criada por ComCampos continua
+// sendo reconhecida por errors.Is como o erro original.
+func (e *ErroDominio) Is(target error) bool {
+	t, ok := target.(*ErroDominio)
+	return ok && t.Codigo == e.Codigo
+}
+
+// ComCampos retorna uma cópia do erro indicando os campos da entrada que causaram a falha.
+func (e *ErroDominio) ComCampos(campos ...ErroCampo) *ErroDominio {
+	copia := *e
+	copia.Campos = append(append([]ErroCampo(nil), e.Campos...), campos...)
+	return &copia
+}
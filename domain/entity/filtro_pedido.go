@@ -0,0 +1,108 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type OrdenacaoPedidos string
+
+const (
+	OrdenarPorData     OrdenacaoPedidos = "data"
+	OrdenarPorDataDesc OrdenacaoPedidos = "-data"
+	OrdenarPorId       OrdenacaoPedidos = "id"
+	OrdenarPorIdDesc   OrdenacaoPedidos = "-id"
+
+	LimitePadraoPedidos = 50
+	LimiteMaximoPedidos = 100
+)
+
+var (
+	ErrFiltroInvalido = NovoErro(ErroValidacao, "filtro_invalido", "Filtro de pedidos inválido")
+)
+
+func (o OrdenacaoPedidos) Valida() bool {
+	switch o {
+	case OrdenarPorData, OrdenarPorDataDesc, OrdenarPorId, OrdenarPorIdDesc:
+		return true
+	}
+	return false
+}
+
+func (o OrdenacaoPedidos) Decrescente() bool {
+	return o == OrdenarPorDataDesc || o == OrdenarPorIdDesc
+}
+
+// FiltroPedidos descreve uma página da listagem de pedidos. Campos vazios não filtram;
+// Limite zero devolve todos os pedidos que atendem ao filtro.
+type FiltroPedidos struct {
+	Status            StatusPedido
+	Cpf               int64
+	PagamentoAprovado *bool
+	CriadoDe          *time.Time
+	CriadoAte         *time.Time
+	Ordenacao         OrdenacaoPedidos
+	Limite            int
+	Cursor            *CursorPedidos
+}
+
+// CursorPedidos identifica o último pedido de uma página, para que a próxima continue
+// logo depois dele na mesma ordenação.
+type CursorPedidos struct {
+	Ordenacao OrdenacaoPedidos `json:"o"`
+	CriadoEm  time.Time        `json:"d"`
+	Id        int              `json:"i"`
+}
+
+type PaginaPedidos struct {
+	Pedidos       []Pedido `json:"pedidos"`
+	ProximoCursor string   `json:"proximo_cursor,omitempty"`
+}
+
+func NovoCursorPedidos(ordenacao OrdenacaoPedidos, ultimo Pedido) CursorPedidos {
+	return CursorPedidos{Ordenacao: ordenacao, CriadoEm: ultimo.CriadoEm, Id: ultimo.Id}
+}
+
+// Codificar gera o valor opaco devolvido ao cliente em proximo_cursor.
+func (c CursorPedidos) Codificar() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodificarCursorPedidos(valor string) (CursorPedidos, error) {
+	var c CursorPedidos
+	b, err := base64.RawURLEncoding.DecodeString(valor)
+	if err != nil {
+		return c, ErrFiltroInvalido.ComCampos(ErroCampo{Campo: "cursor", Mensagem: "cursor inválido"})
+	}
+	if err := json.Unmarshal(b, &c); err != nil || !c.Ordenacao.Valida() || c.Id == 0 {
+		return c, ErrFiltroInvalido.ComCampos(ErroCampo{Campo: "cursor", Mensagem: "cursor inválido"})
+	}
+	return c, nil
+}
+
+// Validar lista os campos do filtro com valores inconsistentes entre si.
+func (f FiltroPedidos) Validar() []ErroCampo {
+	var campos []ErroCampo
+	if f.Status != "" && !f.Status.Valido() {
+		campos = append(campos, ErroCampo{Campo: "status", Mensagem: "status de pedido desconhecido"})
+	}
+	if f.Cpf < 0 {
+		campos = append(campos, ErroCampo{Campo: "cpf", Mensagem: "deve ser positivo"})
+	}
+	if f.CriadoDe != nil && f.CriadoAte != nil && f.CriadoAte.Before(*f.CriadoDe) {
+		campos = append(campos, ErroCampo{Campo: "criado_ate", Mensagem: "deve ser posterior a criado_de"})
+	}
+	if !f.Ordenacao.Valida() {
+		campos = append(campos, ErroCampo{Campo: "ordenar", Mensagem: "use data, -data, id ou -id"})
+	}
+	if f.Limite < 1 || f.Limite > LimiteMaximoPedidos {
+		campos = append(campos, ErroCampo{Campo: "limite", Mensagem: fmt.Sprintf("deve estar entre 1 e %d", LimiteMaximoPedidos)})
+	}
+	if f.Cursor != nil && f.Cursor.Ordenacao != f.Ordenacao {
+		campos = append(campos, ErroCampo{Campo: "cursor", Mensagem: "cursor gerado com outra ordenação"})
+	}
+	return campos
+}
@@ -0,0 +1,55 @@
+package entity
+
+import "time"
+
+type EventoPedido string
+
+const (
+	EventoCriacao      EventoPedido = "criacao"
+	EventoStatus       EventoPedido = "status"
+	EventoPagamento    EventoPedido = "pagamento"
+	EventoCancelamento EventoPedido = "cancelamento"
+	// EventoMigracao marca o estado que os pedidos já tinham quando o histórico foi criado.
+	EventoMigracao EventoPedido = "migracao"
+
+	ResponsavelCliente   = "cliente"
+	ResponsavelPagamento = "servico-pagamento"
+
+	TamanhoMaximoResponsavel = 100
+)
+
+// HistoricoPedido é uma entrada da linha do tempo do pedido: o status e o pagamento como
+// ficaram depois de cada alteração, com quem a fez e quando.
+type HistoricoPedido struct {
+	Evento            EventoPedido `json:"evento"`
+	Status            StatusPedido `json:"status"`
+	PagamentoAprovado bool         `json:"pagamento_aprovado"`
+	Responsavel       string       `json:"responsavel,omitempty"`
+	Motivo            string       `json:"motivo,omitempty"`
+	RegistradoEm      time.Time    `json:"registrado_em"`
+}
+
+// AlteracaoPedido é publicada depois que uma alteração do pedido é gravada. Id identifica
+// o evento no fluxo de eventos e é enviado fora do corpo.
+type AlteracaoPedido struct {
+	Id                string       `json:"-"`
+	PedidoId          int          `json:"pedido_id"`
+	Evento            EventoPedido `json:"evento"`
+	Status            StatusPedido `json:"status"`
+	PagamentoAprovado bool         `json:"pagamento_aprovado"`
+	Responsavel       string       `json:"responsavel,omitempty"`
+	Motivo            string       `json:"motivo,omitempty"`
+	RegistradoEm      time.Time    `json:"registrado_em"`
+}
+
+func NovaAlteracaoPedido(pedidoId int, h HistoricoPedido) AlteracaoPedido {
+	return AlteracaoPedido{
+		PedidoId:          pedidoId,
+		Evento:            h.Evento,
+		Status:            h.Status,
+		PagamentoAprovado: h.PagamentoAprovado,
+		Responsavel:       h.Responsavel,
+		Motivo:            h.Motivo,
+		RegistradoEm:      h.RegistradoEm,
+	}
+}
@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+var (
+	ErrChaveIdempotenciaInvalida    = NovoErro(ErroValidacao, "chave_idempotencia_invalida", "Idempotency-Key inválida")
+	ErrChaveIdempotenciaReutilizada = NovoErro(ErroValidacao, "chave_idempotencia_reutilizada", "Idempotency-Key já usada com outra requisição")
+	ErrRequisicaoEmAndamento        = NovoErro(ErroConflito, "requisicao_em_andamento", "Requisição com esta Idempotency-Key ainda está em processamento")
+)
+
+const (
+	TamanhoMaximoChaveIdempotencia = 255
+
+	// PrazoReservaIdempotencia é o tempo depois do qual uma reserva ainda sem resposta é
+	// considerada abandonada, por exemplo porque o processo caiu durante a requisição. Deve
+	// ficar bem acima do prazo de processamento das requisições.
+	PrazoReservaIdempotencia = time.Minute
+
+	// ValidadeIdempotencia é por quanto tempo uma chave é guardada para novas tentativas.
+	ValidadeIdempotencia = 24 * time.Hour
+)
+
+// Idempotencia registra uma requisição feita com Idempotency-Key. Enquanto a requisição
+// original está em processamento Status é zero; depois guarda a resposta devolvida para
+// que uma nova tentativa com a mesma chave receba exatamente a mesma resposta.
+type Idempotencia struct {
+	Chave       string
+	Hash        string
+	Status      int
+	ContentType string
+	Corpo       []byte
+}
+
+func (i Idempotencia) Concluida() bool {
+	return i.Status != 0
+}
@@ -1,16 +1,104 @@
 package entity
 
+import (
+	"time"
+)
+
+type StatusPedido string
+
+const (
+	StatusRecebido     StatusPedido = "Recebido"
+	StatusEmPreparacao StatusPedido = "Em preparação"
+	StatusPronto       StatusPedido = "Pronto"
+	StatusFinalizado   StatusPedido = "Finalizado"
+	StatusCancelado    StatusPedido = "Cancelado"
+)
+
+var (
+	ErrStatusInvalido          = NovoErro(ErroValidacao, "status_invalido", "Status de pedido inválido")
+	ErrTransicaoStatusInvalida = NovoErro(ErroConflito, "transicao_status_invalida", "Transição de status não permitida")
+	ErrPedidoNaoEncontrado     = NovoErro(ErroNaoEncontrado, "pedido_nao_encontrado", "Pedido não encontrado")
+	ErrPedidoInvalido          = NovoErro(ErroValidacao, "pedido_invalido", "Pedido inválido")
+	ErrPagamentoJaProcessado   = NovoErro(ErroConflito, "pagamento_ja_processado", "Pagamento do pedido já foi processado com outro resultado")
+	ErrClienteNaoCadastrado    = NovoErro(ErroValidacao, "cliente_nao_cadastrado", "Cliente não cadastrado")
+	ErrClienteIndisponivel     = NovoErro(ErroIndisponivel, "cliente_indisponivel", "Serviço de clientes indisponível")
+	ErrAssinaturaInvalida      = NovoErro(ErroNaoAutorizado, "assinatura_invalida", "Assinatura da notificação de pagamento inválida")
+	ErrCancelamentoViaStatus   = NovoErro(ErroValidacao, "cancelamento_via_status", "Pedidos são cancelados por POST /pedido/{id}/cancelamento, com motivo e responsável")
+)
+
+// QuantidadeMaximaItem limita a quantidade de um mesmo produto em uma linha do pedido.
+const QuantidadeMaximaItem = 99
+
+// transicoesPermitidas define para quais status um pedido pode ir a partir do status atual.
+// Cancelado não aparece como destino: o cancelamento registra motivo e reembolso e segue
+// as regras de statusCancelaveis.
+var transicoesPermitidas = map[StatusPedido][]StatusPedido{
+	StatusRecebido:     {StatusEmPreparacao},
+	StatusEmPreparacao: {StatusPronto},
+	StatusPronto:       {StatusFinalizado},
+	StatusFinalizado:   {},
+	StatusCancelado:    {},
+}
+
+// statusCancelaveis são os status em que o pedido ainda não ficou pronto.
+var statusCancelaveis = map[StatusPedido]bool{
+	StatusRecebido:     true,
+	StatusEmPreparacao: true,
+}
+
+func (s StatusPedido) Valido() bool {
+	_, ok := transicoesPermitidas[s]
+	return ok
+}
+
+func (s StatusPedido) PodeTransicionarPara(novo StatusPedido) bool {
+	for _, permitido := range transicoesPermitidas[s] {
+		if permitido == novo {
+			return true
+		}
+	}
+	return false
+}
+
+func (s StatusPedido) PodeSerCancelado() bool {
+	return statusCancelaveis[s]
+}
+
 type Pedido struct {
 	Id                int             `json:"id"`
 	Cpf               int64           `json:"cpf"`
 	Produtos          []ProdutoPedido `json:"produtos"`
-	Status            string          `json:"status"`
+	Status            StatusPedido    `json:"status"`
 	MetodoPagamento   string          `json:"metodo_de_pagamento"`
 	PagamentoAprovado bool            `json:"pagamento_aprovado"`
+	Total             Dinheiro        `json:"total"`
+	TempoDePreparo    int             `json:"tempo_de_preparo"`
+	PrevisaoPronto    *time.Time      `json:"previsao_pronto"`
+	CriadoEm          time.Time       `json:"criado_em"`
 }
 
+// ProdutoPedido é uma linha do pedido. Cada linha tem o próprio Id, de modo que o mesmo
+// produto pode aparecer mais de uma vez com observações diferentes.
 type ProdutoPedido struct {
-	ProdutoId  int    `json:"produto_id"`
-	Quantidade int    `json:"quantidade"`
-	Observacao string `json:"observacao"`
+	Id            int      `json:"id"`
+	ProdutoId     int      `json:"produto_id"`
+	Quantidade    int      `json:"quantidade"`
+	Observacao    string   `json:"observacao"`
+	PrecoUnitario Dinheiro `json:"preco_unitario"`
+	Subtotal      Dinheiro `json:"subtotal"`
+}
+
+// CalcularTotal preenche o subtotal de cada item a partir do preço unitário registrado
+// no pedido e atualiza o total. Itens em moedas diferentes resultam em ErrMoedasDiferentes.
+func (p *Pedido) CalcularTotal() error {
+	total := Centavos(0)
+	for i := range p.Produtos {
+		p.Produtos[i].Subtotal = p.Produtos[i].PrecoUnitario.Multiplicar(p.Produtos[i].Quantidade)
+		var err error
+		if total, err = total.Somar(p.Produtos[i].Subtotal); err != nil {
+			return err
+		}
+	}
+	p.Total = total
+	return nil
 }
@@ -1,10 +1,15 @@
 package entity
 
+var (
+	ErrProdutoNaoEncontrado = NovoErro(ErroNaoEncontrado, "produto_nao_encontrado", "Produto não encontrado")
+	ErrProdutoInvalido      = NovoErro(ErroValidacao, "produto_invalido", "Produto inválido")
+)
+
 type Produto struct {
-	Id             int     `json:"id"`
-	CategoriaId    int     `json:"categoria_id"`
-	Nome           string  `json:"nome"`
-	Descricao      string  `json:"descricao"`
-	Preco          float32 `json:"preco"`
-	TempoDePreparo int     `json:"tempo_de_preparo"`
+	Id             int      `json:"id"`
+	CategoriaId    int      `json:"categoria_id"`
+	Nome           string   `json:"nome"`
+	Descricao      string   `json:"descricao"`
+	Preco          Dinheiro `json:"preco"`
+	TempoDePreparo int      `json:"tempo_de_preparo"`
 }
@@ -0,0 +1,210 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/postgres/*.sql migrations/sqlite/*.sql
+var arquivosMigracao embed.FS
+
+type Migracao struct {
+	Versao int
+	Nome   string
+	Up     string
+	Down   string
+}
+
+type StatusMigracao struct {
+	Versao   int
+	Nome     string
+	Aplicada bool
+}
+
+// driverMigracao isola o que muda entre os dialetos: como travar a base para que só uma
+// instância migre por vez e como registrar cada versão em schema_migrations.
+type driverMigracao interface {
+	Travar() (destravar func() error, err error)
+	CriarTabelaMigracoes() error
+	VersoesAplicadas() (map[int]bool, error)
+	Executar(m Migracao, up bool) error
+}
+
+type Migrador struct {
+	driver    driverMigracao
+	migracoes []Migracao
+	fechar    func() error
+}
+
+func novoMigrador(driver driverMigracao, dialeto string) (*Migrador, error) {
+	migracoes, err := carregarMigracoes(dialeto)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrador{
+		driver:    driver,
+		migracoes: migracoes,
+	}, nil
+}
+
+// Fechar encerra a conexão aberta por NewMigrador. Um migrador criado sobre a conexão de
+// quem chamou não a fecha.
+func (m *Migrador) Fechar() error {
+	if m.fechar == nil {
+		return nil
+	}
+	return m.fechar()
+}
+
+// carregarMigracoes lê os arquivos NNNN_nome.up.sql / NNNN_nome.down.sql do dialeto,
+// ordenados pela versão.
+func carregarMigracoes(dialeto string) ([]Migracao, error) {
+	dir := path.Join("migrations", dialeto)
+	arquivos, err := fs.ReadDir(arquivosMigracao, dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler migrações de %s: %w", dialeto, err)
+	}
+
+	porVersao := map[int]*Migracao{}
+	for _, arquivo := range arquivos {
+		nome := arquivo.Name()
+		base, up := strings.CutSuffix(nome, ".up.sql")
+		if !up {
+			var down bool
+			base, down = strings.CutSuffix(nome, ".down.sql")
+			if !down {
+				continue
+			}
+		}
+
+		numero, descricao, ok := strings.Cut(base, "_")
+		versao, err := strconv.Atoi(numero)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("nome de migração inválido: %s", nome)
+		}
+
+		conteudo, err := fs.ReadFile(arquivosMigracao, path.Join(dir, nome))
+		if err != nil {
+			return nil, err
+		}
+
+		m, existe := porVersao[versao]
+		if !existe {
+			m = &Migracao{Versao: versao, Nome: descricao}
+			porVersao[versao] = m
+		}
+		if m.Nome != descricao {
+			return nil, fmt.Errorf("migrações %s com nomes diferentes para a versão %d", dialeto, versao)
+		}
+		if up {
+			m.Up = string(conteudo)
+		} else {
+			m.Down = string(conteudo)
+		}
+	}
+
+	var migracoes []Migracao
+	for _, m := range porVersao {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migração %d_%s sem arquivo up", m.Versao, m.Nome)
+		}
+		migracoes = append(migracoes, *m)
+	}
+	sort.Slice(migracoes, func(i, j int) bool {
+		return migracoes[i].Versao < migracoes[j].Versao
+	})
+
+	return migracoes, nil
+}
+
+// Aplicar executa, em ordem, todas as migrações ainda não aplicadas e retorna quantas rodaram.
+func (m *Migrador) Aplicar() (int, error) {
+	destravar, err := m.driver.Travar()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao obter lock de migração: %w", err)
+	}
+	defer destravar()
+
+	aplicadas, err := m.versoesAplicadas()
+	if err != nil {
+		return 0, err
+	}
+
+	executadas := 0
+	for _, migracao := range m.migracoes {
+		if aplicadas[migracao.Versao] {
+			continue
+		}
+		if err := m.driver.Executar(migracao, true); err != nil {
+			return executadas, fmt.Errorf("erro ao aplicar migração %d_%s: %w", migracao.Versao, migracao.Nome, err)
+		}
+		fmt.Printf("Migração %d_%s aplicada\n", migracao.Versao, migracao.Nome)
+		executadas++
+	}
+
+	return executadas, nil
+}
+
+// Reverter desfaz as últimas n migrações aplicadas, da mais nova para a mais antiga.
+func (m *Migrador) Reverter(n int) (int, error) {
+	destravar, err := m.driver.Travar()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao obter lock de migração: %w", err)
+	}
+	defer destravar()
+
+	aplicadas, err := m.versoesAplicadas()
+	if err != nil {
+		return 0, err
+	}
+
+	revertidas := 0
+	for i := len(m.migracoes) - 1; i >= 0 && revertidas < n; i-- {
+		migracao := m.migracoes[i]
+		if !aplicadas[migracao.Versao] {
+			continue
+		}
+		if migracao.Down == "" {
+			return revertidas, fmt.Errorf("migração %d_%s não possui arquivo down", migracao.Versao, migracao.Nome)
+		}
+		if err := m.driver.Executar(migracao, false); err != nil {
+			return revertidas, fmt.Errorf("erro ao reverter migração %d_%s: %w", migracao.Versao, migracao.Nome, err)
+		}
+		fmt.Printf("Migração %d_%s revertida\n", migracao.Versao, migracao.Nome)
+		revertidas++
+	}
+
+	return revertidas, nil
+}
+
+func (m *Migrador) Status() ([]StatusMigracao, error) {
+	aplicadas, err := m.versoesAplicadas()
+	if err != nil {
+		return nil, err
+	}
+
+	var status []StatusMigracao
+	for _, migracao := range m.migracoes {
+		status = append(status, StatusMigracao{
+			Versao:   migracao.Versao,
+			Nome:     migracao.Nome,
+			Aplicada: aplicadas[migracao.Versao],
+		})
+	}
+
+	return status, nil
+}
+
+func (m *Migrador) versoesAplicadas() (map[int]bool, error) {
+	if err := m.driver.CriarTabelaMigracoes(); err != nil {
+		return nil, fmt.Errorf("erro ao criar schema_migrations: %w", err)
+	}
+
+	return m.driver.VersoesAplicadas()
+}
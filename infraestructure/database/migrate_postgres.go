@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// lockMigracao é a chave do advisory lock que serializa as migrações entre as réplicas.
+const lockMigracao = 7245001
+
+type driverPostgres struct {
+	db *pgx.Conn
+}
+
+func NewMigradorPostgres(db *pgx.Conn) (*Migrador, error) {
+	return novoMigrador(&driverPostgres{db: db}, "postgres")
+}
+
+func (d *driverPostgres) Travar() (func() error, error) {
+	if _, err := d.db.Exec(context.Background(), "SELECT pg_advisory_lock($1)", lockMigracao); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		_, err := d.db.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockMigracao)
+		return err
+	}, nil
+}
+
+func (d *driverPostgres) CriarTabelaMigracoes() error {
+	_, err := d.db.Exec(context.Background(), `
+    CREATE TABLE IF NOT EXISTS schema_migrations (
+        versao BIGINT PRIMARY KEY,
+        nome VARCHAR(255) NOT NULL,
+        aplicada_em TIMESTAMP NOT NULL DEFAULT NOW()
+    );`)
+	return err
+}
+
+func (d *driverPostgres) VersoesAplicadas() (map[int]bool, error) {
+	rows, err := d.db.Query(context.Background(), "SELECT versao FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aplicadas := map[int]bool{}
+	for rows.Next() {
+		var versao int
+		if err := rows.Scan(&versao); err != nil {
+			return nil, err
+		}
+		aplicadas[versao] = true
+	}
+
+	return aplicadas, rows.Err()
+}
+
+func (d *driverPostgres) Executar(m Migracao, up bool) error {
+	ctx := context.Background()
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if up {
+		if _, err := tx.Exec(ctx, m.Up); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, "INSERT INTO schema_migrations (versao, nome) VALUES ($1, $2)", m.Versao, m.Nome); err != nil {
+			return err
+		}
+	} else {
+		if _, err := tx.Exec(ctx, m.Down); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE versao = $1", m.Versao); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
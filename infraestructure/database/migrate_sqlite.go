@@ -0,0 +1,81 @@
+package database
+
+import (
+	"database/sql"
+	"sync"
+)
+
+// O SQLite só é usado por um processo local, então um mutex basta para serializar as
+// migrações; cada versão ainda roda na sua própria transação.
+var lockMigracaoSqlite sync.Mutex
+
+type driverSqlite struct {
+	db *sql.DB
+}
+
+func NewMigradorSqlite(db *sql.DB) (*Migrador, error) {
+	return novoMigrador(&driverSqlite{db: db}, "sqlite")
+}
+
+func (d *driverSqlite) Travar() (func() error, error) {
+	lockMigracaoSqlite.Lock()
+	return func() error {
+		lockMigracaoSqlite.Unlock()
+		return nil
+	}, nil
+}
+
+func (d *driverSqlite) CriarTabelaMigracoes() error {
+	_, err := d.db.Exec(`
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            versao INTEGER PRIMARY KEY,
+            nome TEXT NOT NULL,
+            aplicada_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+        );`)
+	return err
+}
+
+func (d *driverSqlite) VersoesAplicadas() (map[int]bool, error) {
+	rows, err := d.db.Query("SELECT versao FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aplicadas := map[int]bool{}
+	for rows.Next() {
+		var versao int
+		if err := rows.Scan(&versao); err != nil {
+			return nil, err
+		}
+		aplicadas[versao] = true
+	}
+
+	return aplicadas, rows.Err()
+}
+
+func (d *driverSqlite) Executar(m Migracao, up bool) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if up {
+		if _, err := tx.Exec(m.Up); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO schema_migrations (versao, nome) VALUES (?, ?)", m.Versao, m.Nome); err != nil {
+			return err
+		}
+	} else {
+		if _, err := tx.Exec(m.Down); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM schema_migrations WHERE versao = ?", m.Versao); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
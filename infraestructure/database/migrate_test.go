@@ -0,0 +1,241 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/gomesmatheus/tc-pedido/infraestructure/config"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupMigradorSqlite(t *testing.T) (*sql.DB, *Migrador) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	migrador, err := NewMigradorSqlite(db)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+
+	return db, migrador
+}
+
+func TestMigracoesDosDialetosEstaoAlinhadas(t *testing.T) {
+	postgres, err := carregarMigracoes("postgres")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sqlite, err := carregarMigracoes("sqlite")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(postgres) != len(sqlite) {
+		t.Fatalf("expected same number of migrations, got %d postgres and %d sqlite", len(postgres), len(sqlite))
+	}
+	for i := range postgres {
+		if postgres[i].Versao != sqlite[i].Versao || postgres[i].Nome != sqlite[i].Nome {
+			t.Errorf("migration %d differs: %d_%s vs %d_%s", i, postgres[i].Versao, postgres[i].Nome, sqlite[i].Versao, sqlite[i].Nome)
+		}
+		if postgres[i].Down == "" || sqlite[i].Down == "" {
+			t.Errorf("migration %d_%s has no down file", postgres[i].Versao, postgres[i].Nome)
+		}
+	}
+}
+
+func TestAplicarMigracoes(t *testing.T) {
+	db, migrador := setupMigradorSqlite(t)
+	defer db.Close()
+
+	t.Run("Apply all pending migrations", func(t *testing.T) {
+		n, err := migrador.Aplicar()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != len(migrador.migracoes) {
+			t.Errorf("expected %d migrations applied, got %d", len(migrador.migracoes), n)
+		}
+
+		if _, err := db.Exec(`SELECT previsao_pronto, tempo_preparo_minutos FROM pedidos`); err != nil {
+			t.Errorf("expected pedidos columns to exist: %v", err)
+		}
+
+		var categorias int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM categoria_produtos`).Scan(&categorias); err != nil || categorias != 4 {
+			t.Errorf("expected 4 seeded categorias, got %d (%v)", categorias, err)
+		}
+	})
+
+	t.Run("Applying again is a no-op", func(t *testing.T) {
+		n, err := migrador.Aplicar()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != 0 {
+			t.Errorf("expected no migrations applied, got %d", n)
+		}
+	})
+
+	t.Run("Status lists every migration as applied", func(t *testing.T) {
+		status, err := migrador.Status()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, s := range status {
+			if !s.Aplicada {
+				t.Errorf("expected migration %d_%s to be applied", s.Versao, s.Nome)
+			}
+		}
+	})
+}
+
+func TestReverterMigracoes(t *testing.T) {
+	db, migrador := setupMigradorSqlite(t)
+	defer db.Close()
+
+	if _, err := migrador.Aplicar(); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+
+	// Revertendo até a 0004 o banco volta aos preços em reais, sem idempotencia nem histórico
+	// e com a chave antiga de produto_pedido; as migrações seguintes também são exercitadas.
+	revertidas := len(migrador.migracoes) - 4
+
+	t.Run("Roll back the latest migrations", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO pedidos (cliente_cpf, status, data, metodo_pagamento) VALUES (12345, 'Recebido', CURRENT_TIMESTAMP, 'card')`)
+		if err != nil {
+			t.Fatalf("failed to insert sample pedido: %v", err)
+		}
+		_, err = db.Exec(`INSERT INTO produto_pedido (produto_id, pedido_id, quantidade, observacao) VALUES (1, 1, 1, 'sem cebola'), (1, 1, 2, '')`)
+		if err != nil {
+			t.Fatalf("failed to insert repeated produto lines: %v", err)
+		}
+
+		n, err := migrador.Reverter(revertidas)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != revertidas {
+			t.Errorf("expected %d migrations reverted, got %d", revertidas, n)
+		}
+
+		var linhas, quantidade int
+		var observacao string
+		if err := db.QueryRow(`SELECT COUNT(*), SUM(quantidade), MIN(observacao) FROM produto_pedido WHERE pedido_id = 1`).Scan(&linhas, &quantidade, &observacao); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if linhas != 1 || quantidade != 3 || observacao != "sem cebola" {
+			t.Errorf("expected repeated lines merged into one with quantidade 3, got %d lines, %d, %q", linhas, quantidade, observacao)
+		}
+		for _, tabela := range []string{"idempotencia", "cancelamento_pedido", "pedido_status_historico"} {
+			if _, err := db.Exec(`SELECT 1 FROM ` + tabela); err == nil {
+				t.Errorf("expected %s to be dropped", tabela)
+			}
+		}
+		if _, err := db.Exec(`SELECT preco_centavos FROM produtos`); err == nil {
+			t.Errorf("expected preco_centavos to be dropped")
+		}
+		if _, err := db.Exec(`SELECT preco FROM produtos`); err != nil {
+			t.Errorf("expected preco to be restored: %v", err)
+		}
+
+		status, err := migrador.Status()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, s := range status[len(status)-revertidas:] {
+			if s.Aplicada {
+				t.Errorf("expected migration %d_%s to be pending", s.Versao, s.Nome)
+			}
+		}
+	})
+
+	t.Run("Re-apply after rollback", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO produtos (categoria_id, nome, descricao, preco, tempo_de_preparo_minutos) VALUES (1, 'X-Burger', 'Lanche', 29.99, 10)`)
+		if err != nil {
+			t.Fatalf("failed to insert sample produto: %v", err)
+		}
+
+		n, err := migrador.Aplicar()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != revertidas {
+			t.Errorf("expected %d migrations applied, got %d", revertidas, n)
+		}
+		if _, err := db.Exec(`SELECT chave, hash_requisicao, status_resposta, content_type, corpo_resposta FROM idempotencia`); err != nil {
+			t.Errorf("expected idempotencia to be recreated: %v", err)
+		}
+		if _, err := db.Exec(`SELECT pedido_id, motivo, cancelado_por, cancelado_em, reembolso_centavos, reembolso_status FROM cancelamento_pedido`); err != nil {
+			t.Errorf("expected cancelamento_pedido to be recreated: %v", err)
+		}
+		var evento, statusHistorico string
+		if err := db.QueryRow(`SELECT evento, status FROM pedido_status_historico WHERE pedido_id = 1`).Scan(&evento, &statusHistorico); err != nil {
+			t.Errorf("expected existing pedidos to be backfilled into pedido_status_historico: %v", err)
+		} else if evento != "migracao" || statusHistorico != "Recebido" {
+			t.Errorf("expected a migracao entry with the current status, got %q %q", evento, statusHistorico)
+		}
+
+		var centavos int64
+		if err := db.QueryRow(`SELECT preco_centavos FROM produtos WHERE nome = 'X-Burger'`).Scan(&centavos); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if centavos != 2999 {
+			t.Errorf("expected preco 29.99 to be migrated to 2999 cents, got %d", centavos)
+		}
+
+		_, err = db.Exec(`INSERT INTO produto_pedido (produto_id, pedido_id, quantidade, observacao) VALUES (1, 1, 1, 'sem picles')`)
+		if err != nil {
+			t.Errorf("expected the same produto to be accepted in a new line: %v", err)
+		}
+		var ids int
+		if err := db.QueryRow(`SELECT COUNT(DISTINCT id) FROM produto_pedido WHERE pedido_id = 1`).Scan(&ids); err != nil || ids != 2 {
+			t.Errorf("expected 2 lines with their own ids, got %d (%v)", ids, err)
+		}
+	})
+
+	t.Run("Roll back everything", func(t *testing.T) {
+		n, err := migrador.Reverter(len(migrador.migracoes) + 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != len(migrador.migracoes) {
+			t.Errorf("expected %d migrations reverted, got %d", len(migrador.migracoes), n)
+		}
+
+		if _, err := db.Exec(`SELECT 1 FROM pedidos`); err == nil {
+			t.Errorf("expected pedidos to be dropped")
+		}
+	})
+}
+
+func TestFecharMigrador(t *testing.T) {
+	t.Run("Close the connection opened by NewMigrador", func(t *testing.T) {
+		migrador, err := NewMigrador(config.DriverSqlite, ":memory:")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := migrador.Fechar(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := migrador.Status(); err == nil {
+			t.Errorf("expected the connection to be closed")
+		}
+	})
+
+	t.Run("Keep the caller's connection open", func(t *testing.T) {
+		db, migrador := setupMigradorSqlite(t)
+		defer db.Close()
+
+		if err := migrador.Fechar(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := db.Ping(); err != nil {
+			t.Errorf("expected the connection to stay open, got %v", err)
+		}
+	})
+}
@@ -0,0 +1 @@
+ALTER TABLE produto_pedido DROP COLUMN IF EXISTS preco_unitario;
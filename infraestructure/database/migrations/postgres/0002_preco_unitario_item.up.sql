@@ -0,0 +1 @@
+ALTER TABLE produto_pedido ADD COLUMN IF NOT EXISTS preco_unitario FLOAT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE pedidos DROP COLUMN IF EXISTS previsao_pronto;
+ALTER TABLE pedidos DROP COLUMN IF EXISTS tempo_preparo_minutos;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_produto_pedido_pedido_id;
+DROP INDEX IF EXISTS idx_pedidos_cliente_cpf;
+DROP INDEX IF EXISTS idx_pedidos_status;
+DROP INDEX IF EXISTS idx_pedidos_data_id;
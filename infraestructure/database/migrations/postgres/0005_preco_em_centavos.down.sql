@@ -0,0 +1,7 @@
+ALTER TABLE produto_pedido RENAME COLUMN preco_unitario_centavos TO preco_unitario;
+ALTER TABLE produto_pedido ALTER COLUMN preco_unitario DROP DEFAULT;
+ALTER TABLE produto_pedido ALTER COLUMN preco_unitario TYPE FLOAT USING preco_unitario / 100.0;
+ALTER TABLE produto_pedido ALTER COLUMN preco_unitario SET DEFAULT 0;
+
+ALTER TABLE produtos RENAME COLUMN preco_centavos TO preco;
+ALTER TABLE produtos ALTER COLUMN preco TYPE FLOAT USING preco / 100.0;
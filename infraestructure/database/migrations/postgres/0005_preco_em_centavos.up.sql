@@ -0,0 +1,7 @@
+ALTER TABLE produtos ALTER COLUMN preco TYPE BIGINT USING ROUND(preco * 100)::BIGINT;
+ALTER TABLE produtos RENAME COLUMN preco TO preco_centavos;
+
+ALTER TABLE produto_pedido ALTER COLUMN preco_unitario DROP DEFAULT;
+ALTER TABLE produto_pedido ALTER COLUMN preco_unitario TYPE BIGINT USING ROUND(preco_unitario * 100)::BIGINT;
+ALTER TABLE produto_pedido ALTER COLUMN preco_unitario SET DEFAULT 0;
+ALTER TABLE produto_pedido RENAME COLUMN preco_unitario TO preco_unitario_centavos;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS idempotencia (
+    chave VARCHAR(255) PRIMARY KEY,
+    hash_requisicao CHAR(64) NOT NULL,
+    status_resposta INTEGER,
+    content_type VARCHAR(255),
+    corpo_resposta BYTEA,
+    criado_em TIMESTAMP NOT NULL DEFAULT NOW()
+);
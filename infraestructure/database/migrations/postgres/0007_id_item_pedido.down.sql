@@ -0,0 +1,19 @@
+-- A chave (produto_id, pedido_id) não admite o mesmo produto em linhas diferentes: as
+-- linhas repetidas são somadas na primeira, mantendo a observação dela.
+UPDATE produto_pedido A
+SET quantidade = (
+    SELECT SUM(B.quantidade) FROM produto_pedido B
+    WHERE B.pedido_id = A.pedido_id AND B.produto_id = A.produto_id
+)
+WHERE A.id = (
+    SELECT MIN(C.id) FROM produto_pedido C
+    WHERE C.pedido_id = A.pedido_id AND C.produto_id = A.produto_id
+);
+DELETE FROM produto_pedido A
+WHERE A.id > (
+    SELECT MIN(C.id) FROM produto_pedido C
+    WHERE C.pedido_id = A.pedido_id AND C.produto_id = A.produto_id
+);
+
+ALTER TABLE produto_pedido DROP COLUMN id;
+ALTER TABLE produto_pedido ADD PRIMARY KEY (produto_id, pedido_id);
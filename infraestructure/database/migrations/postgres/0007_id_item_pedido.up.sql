@@ -0,0 +1,2 @@
+ALTER TABLE produto_pedido DROP CONSTRAINT produto_pedido_pkey;
+ALTER TABLE produto_pedido ADD COLUMN id SERIAL PRIMARY KEY;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS cancelamento_pedido (
+    pedido_id INTEGER PRIMARY KEY,
+    motivo VARCHAR(500) NOT NULL,
+    cancelado_por VARCHAR(100) NOT NULL,
+    cancelado_em TIMESTAMP NOT NULL,
+    reembolso_centavos BIGINT,
+    reembolso_status VARCHAR(20),
+
+    CONSTRAINT fk_pedido FOREIGN KEY (pedido_id) REFERENCES pedidos(id)
+);
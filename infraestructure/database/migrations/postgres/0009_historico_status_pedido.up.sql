@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS pedido_status_historico (
+    id SERIAL PRIMARY KEY,
+    pedido_id INTEGER NOT NULL,
+    evento VARCHAR(20) NOT NULL,
+    status VARCHAR(255) NOT NULL,
+    pagamento_aprovado BOOLEAN NOT NULL,
+    responsavel VARCHAR(100) NOT NULL DEFAULT '',
+    motivo VARCHAR(500) NOT NULL DEFAULT '',
+    registrado_em TIMESTAMP NOT NULL,
+
+    CONSTRAINT fk_pedido FOREIGN KEY (pedido_id) REFERENCES pedidos(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pedido_status_historico_pedido_id ON pedido_status_historico (pedido_id, registrado_em, id);
+
+-- Pedidos anteriores ao histórico começam com o estado em que estavam na migração.
+INSERT INTO pedido_status_historico (pedido_id, evento, status, pagamento_aprovado, registrado_em)
+SELECT id, 'migracao', COALESCE(status, 'Recebido'), COALESCE(pagamento_aprovado, FALSE), NOW() FROM pedidos;
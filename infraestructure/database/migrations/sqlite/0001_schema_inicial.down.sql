@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS produto_pedido;
+DROP TABLE IF EXISTS pedidos;
+DROP TABLE IF EXISTS produtos;
+DROP TABLE IF EXISTS categoria_produtos;
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS categoria_produtos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    descricao TEXT NOT NULL UNIQUE
+);
+
+INSERT OR IGNORE INTO categoria_produtos (descricao) VALUES ('Lanche'), ('Acompanhamento'), ('Bebida'), ('Sobremesa');
+
+CREATE TABLE IF NOT EXISTS produtos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    categoria_id INTEGER NOT NULL,
+    nome TEXT NOT NULL UNIQUE,
+    descricao TEXT NOT NULL,
+    preco REAL NOT NULL,
+    tempo_de_preparo_minutos INTEGER NOT NULL,
+    FOREIGN KEY (categoria_id) REFERENCES categoria_produtos(id)
+);
+
+CREATE TABLE IF NOT EXISTS pedidos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    cliente_cpf BIGINT,
+    status TEXT,
+    data TIMESTAMP,
+    metodo_pagamento TEXT,
+    pagamento_aprovado BOOLEAN DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS produto_pedido (
+    produto_id INTEGER NOT NULL,
+    pedido_id INTEGER NOT NULL,
+    quantidade INTEGER NOT NULL,
+    observacao TEXT,
+    PRIMARY KEY (produto_id, pedido_id),
+    FOREIGN KEY (produto_id) REFERENCES produtos(id),
+    FOREIGN KEY (pedido_id) REFERENCES pedidos(id)
+);
@@ -0,0 +1 @@
+ALTER TABLE produto_pedido ADD COLUMN preco_unitario REAL NOT NULL DEFAULT 0;
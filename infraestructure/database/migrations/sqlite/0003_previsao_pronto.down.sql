@@ -0,0 +1,2 @@
+ALTER TABLE pedidos DROP COLUMN previsao_pronto;
+ALTER TABLE pedidos DROP COLUMN tempo_preparo_minutos;
@@ -0,0 +1,2 @@
+ALTER TABLE pedidos ADD COLUMN tempo_preparo_minutos INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE pedidos ADD COLUMN previsao_pronto TIMESTAMP;
@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_pedidos_data_id ON pedidos (data, id);
+CREATE INDEX IF NOT EXISTS idx_pedidos_status ON pedidos (status);
+CREATE INDEX IF NOT EXISTS idx_pedidos_cliente_cpf ON pedidos (cliente_cpf);
+CREATE INDEX IF NOT EXISTS idx_produto_pedido_pedido_id ON produto_pedido (pedido_id);
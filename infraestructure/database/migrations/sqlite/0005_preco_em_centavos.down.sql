@@ -0,0 +1,7 @@
+ALTER TABLE produto_pedido ADD COLUMN preco_unitario REAL NOT NULL DEFAULT 0;
+UPDATE produto_pedido SET preco_unitario = preco_unitario_centavos / 100.0;
+ALTER TABLE produto_pedido DROP COLUMN preco_unitario_centavos;
+
+ALTER TABLE produtos ADD COLUMN preco REAL NOT NULL DEFAULT 0;
+UPDATE produtos SET preco = preco_centavos / 100.0;
+ALTER TABLE produtos DROP COLUMN preco_centavos;
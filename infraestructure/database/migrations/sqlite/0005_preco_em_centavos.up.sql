@@ -0,0 +1,7 @@
+ALTER TABLE produtos ADD COLUMN preco_centavos INTEGER NOT NULL DEFAULT 0;
+UPDATE produtos SET preco_centavos = CAST(ROUND(preco * 100) AS INTEGER);
+ALTER TABLE produtos DROP COLUMN preco;
+
+ALTER TABLE produto_pedido ADD COLUMN preco_unitario_centavos INTEGER NOT NULL DEFAULT 0;
+UPDATE produto_pedido SET preco_unitario_centavos = CAST(ROUND(preco_unitario * 100) AS INTEGER);
+ALTER TABLE produto_pedido DROP COLUMN preco_unitario;
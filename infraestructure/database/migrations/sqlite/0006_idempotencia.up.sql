@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS idempotencia (
+    chave TEXT PRIMARY KEY,
+    hash_requisicao TEXT NOT NULL,
+    status_resposta INTEGER,
+    content_type TEXT,
+    corpo_resposta BLOB,
+    criado_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
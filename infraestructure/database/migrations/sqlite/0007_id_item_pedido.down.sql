@@ -0,0 +1,24 @@
+-- A chave (produto_id, pedido_id) não admite o mesmo produto em linhas diferentes: as
+-- linhas repetidas são somadas, mantendo a observação e o preço da primeira.
+CREATE TABLE produto_pedido_antigo (
+    produto_id INTEGER NOT NULL,
+    pedido_id INTEGER NOT NULL,
+    quantidade INTEGER NOT NULL,
+    observacao TEXT,
+    preco_unitario_centavos INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (produto_id, pedido_id),
+    FOREIGN KEY (produto_id) REFERENCES produtos(id),
+    FOREIGN KEY (pedido_id) REFERENCES pedidos(id)
+);
+INSERT INTO produto_pedido_antigo (produto_id, pedido_id, quantidade, observacao, preco_unitario_centavos)
+SELECT A.produto_id, A.pedido_id, SUM(B.quantidade), A.observacao, A.preco_unitario_centavos
+FROM produto_pedido A
+INNER JOIN produto_pedido B ON B.pedido_id = A.pedido_id AND B.produto_id = A.produto_id
+WHERE A.id = (
+    SELECT MIN(C.id) FROM produto_pedido C
+    WHERE C.pedido_id = A.pedido_id AND C.produto_id = A.produto_id
+)
+GROUP BY A.id;
+DROP TABLE produto_pedido;
+ALTER TABLE produto_pedido_antigo RENAME TO produto_pedido;
+CREATE INDEX IF NOT EXISTS idx_produto_pedido_pedido_id ON produto_pedido (pedido_id);
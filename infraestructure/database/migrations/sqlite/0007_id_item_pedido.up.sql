@@ -0,0 +1,18 @@
+-- O SQLite não altera a chave primária de uma tabela existente; a tabela é recriada.
+CREATE TABLE produto_pedido_novo (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    produto_id INTEGER NOT NULL,
+    pedido_id INTEGER NOT NULL,
+    quantidade INTEGER NOT NULL,
+    observacao TEXT,
+    preco_unitario_centavos INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (produto_id) REFERENCES produtos(id),
+    FOREIGN KEY (pedido_id) REFERENCES pedidos(id)
+);
+INSERT INTO produto_pedido_novo (produto_id, pedido_id, quantidade, observacao, preco_unitario_centavos)
+SELECT produto_id, pedido_id, quantidade, observacao, preco_unitario_centavos
+FROM produto_pedido
+ORDER BY pedido_id, produto_id;
+DROP TABLE produto_pedido;
+ALTER TABLE produto_pedido_novo RENAME TO produto_pedido;
+CREATE INDEX IF NOT EXISTS idx_produto_pedido_pedido_id ON produto_pedido (pedido_id);
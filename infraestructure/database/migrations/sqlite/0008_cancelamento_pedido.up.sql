@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS cancelamento_pedido (
+    pedido_id INTEGER PRIMARY KEY,
+    motivo TEXT NOT NULL,
+    cancelado_por TEXT NOT NULL,
+    cancelado_em TIMESTAMP NOT NULL,
+    reembolso_centavos INTEGER,
+    reembolso_status TEXT,
+    FOREIGN KEY (pedido_id) REFERENCES pedidos(id)
+);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS pedido_status_historico (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pedido_id INTEGER NOT NULL,
+    evento TEXT NOT NULL,
+    status TEXT NOT NULL,
+    pagamento_aprovado BOOLEAN NOT NULL,
+    responsavel TEXT NOT NULL DEFAULT '',
+    motivo TEXT NOT NULL DEFAULT '',
+    registrado_em TIMESTAMP NOT NULL,
+    FOREIGN KEY (pedido_id) REFERENCES pedidos(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pedido_status_historico_pedido_id ON pedido_status_historico (pedido_id, registrado_em, id);
+
+-- Pedidos anteriores ao histórico começam com o estado em que estavam na migração.
+INSERT INTO pedido_status_historico (pedido_id, evento, status, pagamento_aprovado, registrado_em)
+SELECT id, 'migracao', COALESCE(status, 'Recebido'), COALESCE(pagamento_aprovado, 0), CURRENT_TIMESTAMP FROM pedidos;
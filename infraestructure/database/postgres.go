@@ -5,66 +5,79 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/gomesmatheus/tc-pedido/infraestructure/config"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-const (
-	createTables = `
-    CREATE TABLE IF NOT EXISTS categoria_produtos (
-        id SERIAL PRIMARY KEY,
-        descricao VARCHAR(255) NOT NULL UNIQUE
-    );
+const maxRetries = 10
+const retryInterval = 2 * time.Second
 
-    INSERT INTO categoria_produtos (descricao) VALUES ('Lanche'), ('Acompanhamento'), ('Bebida'), ('Sobremesa') ON CONFLICT (descricao) DO NOTHING;
-    SELECT * FROM categoria_produtos;
+// NewPostgresPool abre o pool compartilhado pelos repositórios e aplica as migrações
+// pendentes antes de entregá-lo para uso.
+func NewPostgresPool(cfg config.Config) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.DbUrl)
+	if err != nil {
+		fmt.Println("Error parsing config:", err)
+		return nil, err
+	}
+	poolConfig.MinConns = int32(cfg.DbMinConns)
+	poolConfig.MaxConns = int32(cfg.DbMaxConns)
+	poolConfig.HealthCheckPeriod = cfg.DbHealthCheck
 
-	CREATE TABLE IF NOT EXISTS produtos (
-        id SERIAL PRIMARY KEY,
-        categoria_id INTEGER NOT NULL,
-        nome VARCHAR(255) NOT NULL UNIQUE,
-        descricao VARCHAR(255) NOT NULL,
-        preco FLOAT NOT NULL,
-        tempo_de_preparo_minutos INTEGER NOT NULL,
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, err
+	}
 
-        CONSTRAINT fk_categoria_id FOREIGN KEY(categoria_id) REFERENCES categoria_produtos(id)
-    );
+	for i := 0; i < maxRetries; i++ {
+		if err = pool.Ping(context.Background()); err == nil {
+			break
+		}
+		fmt.Printf("Attempt %d: Error connecting to database: %v\n", i+1, err)
+		time.Sleep(retryInterval)
+	}
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to connect to PostgreSQL after %d attempts: %w", maxRetries, err)
+	}
+
+	if err := aplicarMigracoesPostgres(pool); err != nil {
+		pool.Close()
+		fmt.Println("Error applying migrations:", err)
+		return nil, err
+	}
 
-    CREATE TABLE IF NOT EXISTS pedidos (
-        id SERIAL PRIMARY KEY,
-        cliente_cpf BIGINT,
-        status VARCHAR(255),
-        data TIMESTAMP,
-        metodo_pagamento VARCHAR(255),
-        pagamento_aprovado BOOLEAN DEFAULT FALSE
-    );
+	return pool, nil
+}
 
-    CREATE TABLE IF NOT EXISTS produto_pedido (
-        produto_id INTEGER NOT NULL,
-        pedido_id INTEGER NOT NULL,
-        quantidade INTEGER NOT NULL,
-        observacao VARCHAR,
+// aplicarMigracoesPostgres usa uma única conexão do pool, já que o advisory lock do
+// migrador pertence à sessão que o adquiriu.
+func aplicarMigracoesPostgres(pool *pgxpool.Pool) error {
+	conn, err := pool.Acquire(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
 
-        PRIMARY KEY (produto_id, pedido_id),
-        CONSTRAINT fk_produto FOREIGN KEY (produto_id) REFERENCES produtos(id),
-        CONSTRAINT fk_pedido FOREIGN KEY (pedido_id) REFERENCES pedidos(id)
-    );
-    `
-)
+	migrador, err := NewMigradorPostgres(conn.Conn())
+	if err != nil {
+		return err
+	}
+	_, err = migrador.Aplicar()
+	return err
+}
 
-const maxRetries = 10
-const retryInterval = 2 * time.Second
+// ConectarPostgres abre a conexão sem aplicar migrações, para uso pelo subcomando migrate.
+func ConectarPostgres(url string) (*pgx.Conn, error) {
+	config, err := pgx.ParseConfig(url)
+	if err != nil {
+		fmt.Println("Error parsing config:", err)
+		return nil, err
+	}
 
-func NewPostgresDb(url string) (*pgx.Conn, error) {
 	var db *pgx.Conn
-	var err error
-
 	for i := 0; i < maxRetries; i++ {
-		config, err := pgx.ParseConfig(url)
-		if err != nil {
-			fmt.Println("Error parsing config:", err)
-			return nil, err
-		}
-
 		db, err = pgx.ConnectConfig(context.Background(), config)
 		if err == nil {
 			break
@@ -77,10 +90,5 @@ func NewPostgresDb(url string) (*pgx.Conn, error) {
 		return nil, fmt.Errorf("failed to connect to PostgreSQL after %d attempts: %w", maxRetries, err)
 	}
 
-	if _, err := db.Exec(context.Background(), createTables); err != nil {
-		fmt.Println("Error creating table Pedidos:", err)
-		return nil, err
-	}
-
 	return db, nil
 }
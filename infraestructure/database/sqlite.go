@@ -2,54 +2,35 @@ package database
 
 import (
 	"database/sql"
-	"log"
+	"fmt"
 )
 
-const (
-	createSqliteTables = `
-        CREATE TABLE IF NOT EXISTS produtos (
-            id INTEGER PRIMARY KEY AUTOINCREMENT, -- Use AUTOINCREMENT for auto-generated IDs
-            categoria_id INTEGER NOT NULL,
-            nome TEXT NOT NULL UNIQUE, -- Replace VARCHAR with TEXT (SQLite does not differentiate)
-            descricao TEXT NOT NULL,
-            preco REAL NOT NULL, -- Use REAL instead of FLOAT
-            tempo_de_preparo_minutos INTEGER NOT NULL,
-            FOREIGN KEY (categoria_id) REFERENCES categoria_produtos(id)
-        );
-
-        CREATE TABLE IF NOT EXISTS pedidos (
-            id INTEGER PRIMARY KEY AUTOINCREMENT,
-            cliente_cpf BIGINT,
-            status TEXT,
-            data TIMESTAMP,
-            metodo_pagamento TEXT,
-            pagamento_aprovado BOOLEAN DEFAULT 0, -- Use 0/1 for BOOLEAN
-            FOREIGN KEY (cliente_cpf) REFERENCES clientes(cpf)
-        );
-
-        CREATE TABLE IF NOT EXISTS produto_pedido (
-            produto_id INTEGER NOT NULL,
-            pedido_id INTEGER NOT NULL,
-            quantidade INTEGER NOT NULL,
-            observacao TEXT,
-            PRIMARY KEY (produto_id, pedido_id),
-            FOREIGN KEY (produto_id) REFERENCES produtos(id),
-            FOREIGN KEY (pedido_id) REFERENCES pedidos(id)
-        );
-    `
-)
+func NewSqliteDB(path string) (*sql.DB, error) {
+	db, err := AbrirSqlite(path)
+	if err != nil {
+		return nil, err
+	}
 
-func NewSqliteDB() *sql.DB {
-	db, err := sql.Open("sqlite3", ":memory:")
-	// db, err := sql.Open("sqlite3", "./local.db")
+	migrador, err := NewMigradorSqlite(db)
 	if err != nil {
-		log.Fatalf("Failed to connect to the database: %v", err)
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
 	}
+	if _, err := migrador.Aplicar(); err != nil {
+		return nil, fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	return db, nil
+}
 
-	_, err = db.Exec(createSqliteTables)
+// AbrirSqlite abre a base sem aplicar migrações, para uso pelo subcomando migrate.
+func AbrirSqlite(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", path)
 	if err != nil {
-		log.Fatalf("Failed to create table: %v", err)
+		return nil, fmt.Errorf("failed to connect to the database: %w", err)
 	}
 
-	return db
+	// Cada conexão com ":memory:" abre uma base nova, então o pool fica com uma só.
+	db.SetMaxOpenConns(1)
+
+	return db, nil
 }
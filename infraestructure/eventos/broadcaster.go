@@ -0,0 +1,127 @@
+package eventos
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+const (
+	// CapacidadeRecentes é quantas alterações ficam em memória para retomar um fluxo pelo
+	// Last-Event-ID.
+	CapacidadeRecentes = 1000
+
+	// capacidadeAssinatura limita as alterações ainda não entregues a um assinante. Um
+	// assinante que fica mais atrasado que isso é desconectado e retoma pelo Last-Event-ID.
+	capacidadeAssinatura = 64
+)
+
+// Broadcaster distribui as alterações de pedidos aos assinantes conectados a esta instância.
+// Os ids levam a geração do processo, para que um id de uma execução anterior não seja
+// confundido com os da atual.
+type Broadcaster struct {
+	mu         sync.Mutex
+	geracao    string
+	sequencia  uint64
+	capacidade int
+	recentes   []entity.AlteracaoPedido
+	assinantes map[*assinante]struct{}
+}
+
+type assinante struct {
+	pedidoId   int
+	alteracoes chan entity.AlteracaoPedido
+}
+
+func NewBroadcaster(capacidade int) *Broadcaster {
+	return &Broadcaster{
+		geracao:    strconv.FormatInt(time.Now().UnixNano(), 36),
+		capacidade: capacidade,
+		assinantes: map[*assinante]struct{}{},
+	}
+}
+
+// Publicar numera a alteração e a entrega sem bloquear: quem publica não espera por
+// assinantes lentos.
+func (b *Broadcaster) Publicar(a entity.AlteracaoPedido) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sequencia++
+	a.Id = b.geracao + "-" + strconv.FormatUint(b.sequencia, 10)
+	b.recentes = append(b.recentes, a)
+	if len(b.recentes) > b.capacidade {
+		b.recentes = b.recentes[len(b.recentes)-b.capacidade:]
+	}
+
+	for s := range b.assinantes {
+		if s.pedidoId != 0 && s.pedidoId != a.PedidoId {
+			continue
+		}
+		select {
+		case s.alteracoes <- a:
+		default:
+			b.remover(s)
+		}
+	}
+}
+
+// Assinar registra um assinante para as alterações de um pedido, ou de todos os pedidos
+// quando pedidoId é zero. As alterações em memória posteriores a ultimoId são entregues
+// antes das novas. continuo é falso quando ultimoId não pode mais ser retomado e o
+// assinante pode ter perdido alterações. O canal é fechado por cancelar ou quando o
+// assinante fica atrasado demais.
+func (b *Broadcaster) Assinar(pedidoId int, ultimoId string) (alteracoes <-chan entity.AlteracaoPedido, continuo bool, cancelar func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var pendentes []entity.AlteracaoPedido
+	recentes, continuo := b.desde(ultimoId)
+	for _, a := range recentes {
+		if pedidoId == 0 || a.PedidoId == pedidoId {
+			pendentes = append(pendentes, a)
+		}
+	}
+
+	s := &assinante{pedidoId: pedidoId, alteracoes: make(chan entity.AlteracaoPedido, len(pendentes)+capacidadeAssinatura)}
+	for _, a := range pendentes {
+		s.alteracoes <- a
+	}
+	b.assinantes[s] = struct{}{}
+
+	return s.alteracoes, continuo, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remover(s)
+	}
+}
+
+// desde devolve as alterações publicadas depois de ultimoId, desde que a seguinte a ele
+// ainda esteja em memória.
+func (b *Broadcaster) desde(ultimoId string) ([]entity.AlteracaoPedido, bool) {
+	if ultimoId == "" {
+		return nil, true
+	}
+
+	geracao, sequencia, _ := strings.Cut(ultimoId, "-")
+	ultima, err := strconv.ParseUint(sequencia, 10, 64)
+	if err != nil || geracao != b.geracao || ultima > b.sequencia {
+		return nil, false
+	}
+
+	primeira := b.sequencia - uint64(len(b.recentes)) + 1
+	if ultima+1 < primeira {
+		return nil, false
+	}
+	return b.recentes[ultima+1-primeira:], true
+}
+
+func (b *Broadcaster) remover(s *assinante) {
+	if _, ok := b.assinantes[s]; ok {
+		delete(b.assinantes, s)
+		close(s.alteracoes)
+	}
+}
@@ -0,0 +1,144 @@
+package eventos
+
+import (
+	"testing"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+func receber(t *testing.T, alteracoes <-chan entity.AlteracaoPedido) []entity.AlteracaoPedido {
+	t.Helper()
+	var recebidas []entity.AlteracaoPedido
+	for {
+		select {
+		case a, ok := <-alteracoes:
+			if !ok {
+				return recebidas
+			}
+			recebidas = append(recebidas, a)
+		default:
+			return recebidas
+		}
+	}
+}
+
+func pedidos(alteracoes []entity.AlteracaoPedido) []int {
+	var ids []int
+	for _, a := range alteracoes {
+		ids = append(ids, a.PedidoId)
+	}
+	return ids
+}
+
+func TestPublicar(t *testing.T) {
+	b := NewBroadcaster(CapacidadeRecentes)
+	todos, _, cancelarTodos := b.Assinar(0, "")
+	defer cancelarTodos()
+	um, _, cancelarUm := b.Assinar(2, "")
+	defer cancelarUm()
+
+	for _, id := range []int{1, 2, 3} {
+		b.Publicar(entity.AlteracaoPedido{PedidoId: id, Status: entity.StatusRecebido})
+	}
+
+	recebidas := receber(t, todos)
+	if len(recebidas) != 3 {
+		t.Fatalf("expected 3 alteracoes, got %v", pedidos(recebidas))
+	}
+	if recebidas[0].Id == "" || recebidas[0].Id == recebidas[1].Id {
+		t.Errorf("expected distinct ids, got %q and %q", recebidas[0].Id, recebidas[1].Id)
+	}
+	if doPedido := receber(t, um); len(doPedido) != 1 || doPedido[0].PedidoId != 2 {
+		t.Errorf("expected only pedido 2, got %v", pedidos(doPedido))
+	}
+}
+
+func TestAssinarComUltimoId(t *testing.T) {
+	b := NewBroadcaster(3)
+	primeira, _, cancelar := b.Assinar(0, "")
+	for _, id := range []int{1, 2, 3} {
+		b.Publicar(entity.AlteracaoPedido{PedidoId: id})
+	}
+	ids := receber(t, primeira)
+	cancelar()
+
+	t.Run("Resume after the last received event", func(t *testing.T) {
+		alteracoes, continuo, cancelar := b.Assinar(0, ids[0].Id)
+		defer cancelar()
+
+		if !continuo {
+			t.Error("expected the stream to be resumable")
+		}
+		if recebidas := pedidos(receber(t, alteracoes)); len(recebidas) != 2 || recebidas[0] != 2 || recebidas[1] != 3 {
+			t.Errorf("expected pedidos 2 and 3 to be replayed, got %v", recebidas)
+		}
+	})
+
+	t.Run("Resume a single pedido", func(t *testing.T) {
+		alteracoes, continuo, cancelar := b.Assinar(3, ids[0].Id)
+		defer cancelar()
+
+		if recebidas := pedidos(receber(t, alteracoes)); !continuo || len(recebidas) != 1 || recebidas[0] != 3 {
+			t.Errorf("expected only pedido 3 to be replayed, got %v (continuo %v)", recebidas, continuo)
+		}
+	})
+
+	t.Run("Up to date", func(t *testing.T) {
+		alteracoes, continuo, cancelar := b.Assinar(0, ids[2].Id)
+		defer cancelar()
+
+		if recebidas := receber(t, alteracoes); !continuo || len(recebidas) != 0 {
+			t.Errorf("expected nothing to replay, got %v (continuo %v)", pedidos(recebidas), continuo)
+		}
+	})
+
+	t.Run("Evicted event cannot be resumed", func(t *testing.T) {
+		b.Publicar(entity.AlteracaoPedido{PedidoId: 4})
+		b.Publicar(entity.AlteracaoPedido{PedidoId: 5})
+
+		alteracoes, continuo, cancelar := b.Assinar(0, ids[0].Id)
+		defer cancelar()
+
+		if recebidas := receber(t, alteracoes); continuo || len(recebidas) != 0 {
+			t.Errorf("expected a non resumable stream, got %v (continuo %v)", pedidos(recebidas), continuo)
+		}
+	})
+
+	t.Run("Id from another process", func(t *testing.T) {
+		_, continuo, cancelar := NewBroadcaster(3).Assinar(0, ids[2].Id)
+		defer cancelar()
+
+		if continuo {
+			t.Error("expected an id from another generation not to be resumable")
+		}
+	})
+
+	t.Run("Malformed id", func(t *testing.T) {
+		_, continuo, cancelar := b.Assinar(0, "abc")
+		defer cancelar()
+
+		if continuo {
+			t.Error("expected a malformed id not to be resumable")
+		}
+	})
+}
+
+func TestAssinanteAtrasado(t *testing.T) {
+	b := NewBroadcaster(CapacidadeRecentes)
+	alteracoes, _, cancelar := b.Assinar(0, "")
+
+	for i := 0; i < capacidadeAssinatura+1; i++ {
+		b.Publicar(entity.AlteracaoPedido{PedidoId: i + 1})
+	}
+
+	recebidas := 0
+	for range alteracoes {
+		recebidas++
+	}
+	if recebidas != capacidadeAssinatura {
+		t.Errorf("expected %d alteracoes before the channel is closed, got %d", capacidadeAssinatura, recebidas)
+	}
+
+	cancelar()
+	b.Publicar(entity.AlteracaoPedido{PedidoId: 99})
+}
@@ -0,0 +1,8 @@
+package eventos
+
+import "github.com/gomesmatheus/tc-pedido/domain/entity"
+
+// Publicador recebe as alterações de pedidos depois que elas são gravadas.
+type Publicador interface {
+	Publicar(a entity.AlteracaoPedido)
+}
@@ -0,0 +1,25 @@
+package eventos
+
+import (
+	"sync"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+// PublicadorFake guarda em memória as alterações publicadas.
+type PublicadorFake struct {
+	mu         sync.Mutex
+	alteracoes []entity.AlteracaoPedido
+}
+
+func (p *PublicadorFake) Publicar(a entity.AlteracaoPedido) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.alteracoes = append(p.alteracoes, a)
+}
+
+func (p *PublicadorFake) Alteracoes() []entity.AlteracaoPedido {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]entity.AlteracaoPedido(nil), p.alteracoes...)
+}
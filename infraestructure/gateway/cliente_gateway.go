@@ -0,0 +1,103 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+type ClienteHttpGateway struct {
+	BaseUrl       string
+	Client        *http.Client
+	MaxTentativas int
+	Backoff       time.Duration
+}
+
+func NewClienteHttpGateway(baseUrl string, timeout time.Duration, maxTentativas int, backoff time.Duration) *ClienteHttpGateway {
+	return &ClienteHttpGateway{
+		BaseUrl:       baseUrl,
+		Client:        &http.Client{Timeout: timeout},
+		MaxTentativas: maxTentativas,
+		Backoff:       backoff,
+	}
+}
+
+// ClienteCadastrado consulta o serviço de clientes. Só o 404 indica cliente não cadastrado;
+// qualquer outra resposta inesperada, como 401, 403 ou 429, retorna ErrClienteIndisponivel
+// sem nova tentativa. Falhas de rede e respostas 5xx são repetidas com backoff exponencial;
+// esgotadas as tentativas, retorna ErrClienteIndisponivel. Também retorna
+// ErrClienteIndisponivel quando o prazo de ctx acaba ou não comporta a próxima espera;
+// ctx.Err() só é retornado quando quem chamou cancelou ctx.
+func (g *ClienteHttpGateway) ClienteCadastrado(ctx context.Context, cpf int64) (bool, error) {
+	endpoint := fmt.Sprintf("%s/cliente/%d", g.BaseUrl, cpf)
+	espera := g.Backoff
+
+	var ultimoErro error
+	for tentativa := 1; tentativa <= g.MaxTentativas; tentativa++ {
+		if tentativa > 1 {
+			if prazo, ok := ctx.Deadline(); ok && time.Until(prazo) < espera {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return false, erroContexto(ctx, ultimoErro)
+			case <-time.After(espera):
+			}
+			espera *= 2
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return false, err
+		}
+
+		resp, err := g.Client.Do(req)
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return false, erroContexto(ctx, ultimoErro)
+		}
+		if err != nil {
+			slog.Warn("Cliente-app request failed", "tentativa", tentativa, "erro", err)
+			ultimoErro = err
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 500 {
+			ultimoErro = fmt.Errorf("status %d", resp.StatusCode)
+			slog.Warn("Cliente-app request failed", "tentativa", tentativa, "erro", ultimoErro)
+			continue
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return true, nil
+		case http.StatusNotFound:
+			return false, nil
+		}
+		err = fmt.Errorf("%w: status %d", entity.ErrClienteIndisponivel, resp.StatusCode)
+		slog.Warn("Cliente-app request failed", "tentativa", tentativa, "erro", err)
+		return false, err
+	}
+
+	return false, fmt.Errorf("%w: %v", entity.ErrClienteIndisponivel, ultimoErro)
+}
+
+// erroContexto separa o cancelamento feito por quem chamou, que é repassado como está, do
+// prazo esgotado, que conta como serviço de clientes indisponível.
+func erroContexto(ctx context.Context, ultimoErro error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return ctx.Err()
+	}
+	if ultimoErro == nil {
+		ultimoErro = ctx.Err()
+	}
+	return fmt.Errorf("%w: %v", entity.ErrClienteIndisponivel, ultimoErro)
+}
@@ -0,0 +1,15 @@
+package gateway
+
+import "context"
+
+type ClienteGatewayFake struct {
+	Cadastrados map[int64]bool
+	Err         error
+}
+
+func (g *ClienteGatewayFake) ClienteCadastrado(ctx context.Context, cpf int64) (bool, error) {
+	if g.Err != nil {
+		return false, g.Err
+	}
+	return g.Cadastrados[cpf], nil
+}
@@ -0,0 +1,169 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+func TestClienteCadastrado(t *testing.T) {
+	t.Run("Registered cliente", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/cliente/12345" {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		g := NewClienteHttpGateway(server.URL, time.Second, 3, time.Millisecond)
+		cadastrado, err := g.ClienteCadastrado(context.Background(), 12345)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !cadastrado {
+			t.Errorf("expected cliente to be registered")
+		}
+	})
+
+	t.Run("Unregistered cliente", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		g := NewClienteHttpGateway(server.URL, time.Second, 3, time.Millisecond)
+		cadastrado, err := g.ClienteCadastrado(context.Background(), 12345)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cadastrado {
+			t.Errorf("expected cliente not to be registered")
+		}
+	})
+
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests} {
+		t.Run(fmt.Sprintf("Status %d is a dependency error", status), func(t *testing.T) {
+			chamadas := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				chamadas++
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			g := NewClienteHttpGateway(server.URL, time.Second, 3, time.Millisecond)
+			_, err := g.ClienteCadastrado(context.Background(), 12345)
+			if !errors.Is(err, entity.ErrClienteIndisponivel) {
+				t.Errorf("expected ErrClienteIndisponivel, got %v", err)
+			}
+			if chamadas != 1 {
+				t.Errorf("expected no retry, got %d calls", chamadas)
+			}
+		})
+	}
+
+	t.Run("Retry after server error", func(t *testing.T) {
+		chamadas := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			chamadas++
+			if chamadas < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		g := NewClienteHttpGateway(server.URL, time.Second, 3, time.Millisecond)
+		cadastrado, err := g.ClienteCadastrado(context.Background(), 12345)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !cadastrado || chamadas != 3 {
+			t.Errorf("expected success on third attempt, got %v after %d calls", cadastrado, chamadas)
+		}
+	})
+
+	t.Run("Service unavailable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		g := NewClienteHttpGateway(server.URL, time.Second, 2, time.Millisecond)
+		_, err := g.ClienteCadastrado(context.Background(), 12345)
+		if !errors.Is(err, entity.ErrClienteIndisponivel) {
+			t.Errorf("expected ErrClienteIndisponivel, got %v", err)
+		}
+	})
+
+	t.Run("Deadline too short for the next retry", func(t *testing.T) {
+		chamadas := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			chamadas++
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		g := NewClienteHttpGateway(server.URL, time.Second, 5, time.Second)
+		inicio := time.Now()
+		_, err := g.ClienteCadastrado(ctx, 12345)
+		if !errors.Is(err, entity.ErrClienteIndisponivel) {
+			t.Errorf("expected ErrClienteIndisponivel, got %v", err)
+		}
+		if chamadas != 1 {
+			t.Errorf("expected a single call before the deadline, got %d", chamadas)
+		}
+		if decorrido := time.Since(inicio); decorrido >= 50*time.Millisecond {
+			t.Errorf("expected to give up without waiting for the deadline, took %s", decorrido)
+		}
+	})
+
+	t.Run("Deadline reached during the request", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		g := NewClienteHttpGateway(server.URL, time.Second, 3, time.Millisecond)
+		_, err := g.ClienteCadastrado(ctx, 12345)
+		if !errors.Is(err, entity.ErrClienteIndisponivel) {
+			t.Errorf("expected ErrClienteIndisponivel, got %v", err)
+		}
+	})
+
+	t.Run("Cancelled context stops retries", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		chamadas := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			chamadas++
+			cancel()
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		g := NewClienteHttpGateway(server.URL, time.Second, 5, time.Millisecond)
+		_, err := g.ClienteCadastrado(ctx, 12345)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		if errors.Is(err, entity.ErrClienteIndisponivel) {
+			t.Errorf("expected the cancellation not to be reported as ErrClienteIndisponivel")
+		}
+		if chamadas != 1 {
+			t.Errorf("expected a single call before the cancellation, got %d", chamadas)
+		}
+	})
+}
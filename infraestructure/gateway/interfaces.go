@@ -0,0 +1,15 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+type ClienteGateway interface {
+	ClienteCadastrado(ctx context.Context, cpf int64) (bool, error)
+}
+
+type PagamentoGateway interface {
+	SolicitarReembolso(ctx context.Context, s entity.SolicitacaoReembolso) error
+}
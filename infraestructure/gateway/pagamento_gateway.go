@@ -0,0 +1,55 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+type PagamentoHttpGateway struct {
+	BaseUrl string
+	Client  *http.Client
+}
+
+func NewPagamentoHttpGateway(baseUrl string, timeout time.Duration) *PagamentoHttpGateway {
+	return &PagamentoHttpGateway{
+		BaseUrl: baseUrl,
+		Client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// SolicitarReembolso envia a solicitação ao serviço de pagamentos. A Idempotency-Key é
+// derivada do pedido, para que uma nova tentativa não gere um segundo reembolso. Falhas
+// de rede e respostas que não sejam 2xx retornam ErrPagamentoIndisponivel.
+func (g *PagamentoHttpGateway) SolicitarReembolso(ctx context.Context, s entity.SolicitacaoReembolso) error {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.BaseUrl+"/reembolso", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", fmt.Sprintf("reembolso-pedido-%d", s.PedidoId))
+
+	resp, err := g.Client.Do(req)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", entity.ErrPagamentoIndisponivel, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: status %d", entity.ErrPagamentoIndisponivel, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+// PagamentoGatewayFake guarda em memória os reembolsos solicitados.
+type PagamentoGatewayFake struct {
+	Err error
+
+	mu         sync.Mutex
+	reembolsos []entity.SolicitacaoReembolso
+}
+
+func (g *PagamentoGatewayFake) SolicitarReembolso(ctx context.Context, s entity.SolicitacaoReembolso) error {
+	if g.Err != nil {
+		return g.Err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.reembolsos = append(g.reembolsos, s)
+	return nil
+}
+
+func (g *PagamentoGatewayFake) Reembolsos() []entity.SolicitacaoReembolso {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]entity.SolicitacaoReembolso(nil), g.reembolsos...)
+}
@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+func TestSolicitarReembolso(t *testing.T) {
+	solicitacao := entity.SolicitacaoReembolso{PedidoId: 7, Valor: entity.Centavos(2550), Motivo: "cliente desistiu"}
+
+	t.Run("Reembolso aceito", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != "/reembolso" {
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			}
+			if r.Header.Get("Idempotency-Key") != "reembolso-pedido-7" {
+				t.Errorf("unexpected Idempotency-Key %q", r.Header.Get("Idempotency-Key"))
+			}
+			var recebida entity.SolicitacaoReembolso
+			if err := json.NewDecoder(r.Body).Decode(&recebida); err != nil || recebida != solicitacao {
+				t.Errorf("unexpected body %+v (%v)", recebida, err)
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		g := NewPagamentoHttpGateway(server.URL, time.Second)
+		if err := g.SolicitarReembolso(context.Background(), solicitacao); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Reembolso recusado", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		g := NewPagamentoHttpGateway(server.URL, time.Second)
+		if err := g.SolicitarReembolso(context.Background(), solicitacao); !errors.Is(err, entity.ErrPagamentoIndisponivel) {
+			t.Errorf("expected ErrPagamentoIndisponivel, got %v", err)
+		}
+	})
+
+	t.Run("Service unavailable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		g := NewPagamentoHttpGateway(server.URL, time.Second)
+		if err := g.SolicitarReembolso(context.Background(), solicitacao); !errors.Is(err, entity.ErrPagamentoIndisponivel) {
+			t.Errorf("expected ErrPagamentoIndisponivel, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// codigoViolacaoUnicidade é o SQLSTATE do postgres para unique_violation.
+const codigoViolacaoUnicidade = "23505"
+
+type CategoriaDbConnection struct {
+	Db *pgxpool.Pool
+}
+
+func (repo *CategoriaDbConnection) CriarCategoria(ctx context.Context, c entity.Categoria) (entity.Categoria, error) {
+	err := repo.Db.QueryRow(ctx, "INSERT INTO categoria_produtos (descricao) VALUES ($1) RETURNING id", c.Descricao).Scan(&c.Id)
+	if violacaoUnicidade(err) {
+		return c, entity.ErrCategoriaDuplicada
+	}
+	if err != nil {
+		fmt.Println("Erro ao inserir categoria na base de dados", err)
+	}
+	return c, err
+}
+
+func (repo *CategoriaDbConnection) RecuperarCategorias(ctx context.Context) ([]entity.Categoria, error) {
+	categorias := []entity.Categoria{}
+	rows, err := repo.Db.Query(ctx, "SELECT id, descricao FROM categoria_produtos ORDER BY id")
+	if err != nil {
+		fmt.Println("Erro ao recuperar categorias:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c entity.Categoria
+		if err = rows.Scan(&c.Id, &c.Descricao); err != nil {
+			fmt.Println("Erro fazendo scanning de categoria:", err)
+			return nil, err
+		}
+		categorias = append(categorias, c)
+	}
+
+	return categorias, rows.Err()
+}
+
+func (repo *CategoriaDbConnection) RecuperarCategoria(ctx context.Context, id int) (entity.Categoria, error) {
+	var c entity.Categoria
+	err := repo.Db.QueryRow(ctx, "SELECT id, descricao FROM categoria_produtos WHERE id = $1", id).Scan(&c.Id, &c.Descricao)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c, entity.ErrCategoriaNaoEncontrada
+	}
+	if err != nil {
+		fmt.Println("Erro ao buscar categoria por id", id, err)
+	}
+	return c, err
+}
+
+func (repo *CategoriaDbConnection) AtualizarCategoria(ctx context.Context, id int, c entity.Categoria) error {
+	tag, err := repo.Db.Exec(ctx, "UPDATE categoria_produtos SET descricao = $1 WHERE id = $2", c.Descricao, id)
+	if violacaoUnicidade(err) {
+		return entity.ErrCategoriaDuplicada
+	}
+	if err != nil {
+		fmt.Println("Erro ao atualizar categoria na base de dados", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ErrCategoriaNaoEncontrada
+	}
+	return nil
+}
+
+// DeletarCategoria só remove a categoria se nenhum produto a referencia, na mesma instrução,
+// para que um produto cadastrado em paralelo não fique sem categoria.
+func (repo *CategoriaDbConnection) DeletarCategoria(ctx context.Context, id int) error {
+	tag, err := repo.Db.Exec(ctx, "DELETE FROM categoria_produtos WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM produtos WHERE categoria_id = $1)", id)
+	if err != nil {
+		fmt.Println("Erro ao deletar categoria da base de dados", err)
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var existe bool
+	if err = repo.Db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM categoria_produtos WHERE id = $1)", id).Scan(&existe); err != nil {
+		fmt.Println("Erro ao buscar categoria por id", id, err)
+		return err
+	}
+	if existe {
+		return entity.ErrCategoriaEmUso
+	}
+	return entity.ErrCategoriaNaoEncontrada
+}
+
+func violacaoUnicidade(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == codigoViolacaoUnicidade
+}
@@ -0,0 +1,99 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+	"github.com/mattn/go-sqlite3"
+)
+
+type CategoriaDbMock struct {
+	Db *sql.DB
+}
+
+func (repo *CategoriaDbMock) CriarCategoria(ctx context.Context, c entity.Categoria) (entity.Categoria, error) {
+	err := repo.Db.QueryRowContext(ctx, "INSERT INTO categoria_produtos (descricao) VALUES (?) RETURNING id", c.Descricao).Scan(&c.Id)
+	if violacaoUnicidadeSqlite(err) {
+		return c, entity.ErrCategoriaDuplicada
+	}
+	if err != nil {
+		return c, fmt.Errorf("erro ao inserir categoria na base de dados: %w", err)
+	}
+	return c, nil
+}
+
+func (repo *CategoriaDbMock) RecuperarCategorias(ctx context.Context) ([]entity.Categoria, error) {
+	categorias := []entity.Categoria{}
+	rows, err := repo.Db.QueryContext(ctx, "SELECT id, descricao FROM categoria_produtos ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar categorias: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c entity.Categoria
+		if err := rows.Scan(&c.Id, &c.Descricao); err != nil {
+			return nil, fmt.Errorf("erro ao fazer scanning de categoria: %w", err)
+		}
+		categorias = append(categorias, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar pelas categorias: %w", err)
+	}
+
+	return categorias, nil
+}
+
+func (repo *CategoriaDbMock) RecuperarCategoria(ctx context.Context, id int) (entity.Categoria, error) {
+	var c entity.Categoria
+	err := repo.Db.QueryRowContext(ctx, "SELECT id, descricao FROM categoria_produtos WHERE id = ?", id).Scan(&c.Id, &c.Descricao)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c, entity.ErrCategoriaNaoEncontrada
+	}
+	if err != nil {
+		return c, fmt.Errorf("erro ao buscar categoria por id (%d): %v", id, err)
+	}
+	return c, nil
+}
+
+func (repo *CategoriaDbMock) AtualizarCategoria(ctx context.Context, id int, c entity.Categoria) error {
+	result, err := repo.Db.ExecContext(ctx, "UPDATE categoria_produtos SET descricao = ? WHERE id = ?", c.Descricao, id)
+	if violacaoUnicidadeSqlite(err) {
+		return entity.ErrCategoriaDuplicada
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar categoria na base de dados: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return entity.ErrCategoriaNaoEncontrada
+	}
+	return nil
+}
+
+func (repo *CategoriaDbMock) DeletarCategoria(ctx context.Context, id int) error {
+	result, err := repo.Db.ExecContext(ctx, "DELETE FROM categoria_produtos WHERE id = ?1 AND NOT EXISTS (SELECT 1 FROM produtos WHERE categoria_id = ?1)", id)
+	if err != nil {
+		return fmt.Errorf("erro ao deletar categoria da base de dados: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	var existe bool
+	if err = repo.Db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM categoria_produtos WHERE id = ?)", id).Scan(&existe); err != nil {
+		return fmt.Errorf("erro ao buscar categoria por id: %w", err)
+	}
+	if existe {
+		return entity.ErrCategoriaEmUso
+	}
+	return entity.ErrCategoriaNaoEncontrada
+}
+
+func violacaoUnicidadeSqlite(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
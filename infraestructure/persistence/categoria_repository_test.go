@@ -0,0 +1,176 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupCategoriaTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	_, err = db.Exec(`
+	CREATE TABLE categoria_produtos (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		descricao TEXT NOT NULL UNIQUE
+	);
+	CREATE TABLE produtos (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		categoria_id INTEGER NOT NULL
+	);
+	`)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	return db
+}
+
+func TestCriarCategoria(t *testing.T) {
+	db := setupCategoriaTestDB(t)
+	defer db.Close()
+
+	repo := &CategoriaDbMock{Db: db}
+
+	t.Run("Create a valid categoria", func(t *testing.T) {
+		categoria, err := repo.CriarCategoria(context.Background(), entity.Categoria{Descricao: "Lanche"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if categoria.Id == 0 {
+			t.Errorf("expected categoria ID to be generated, got 0")
+		}
+	})
+
+	t.Run("Reject duplicated descricao", func(t *testing.T) {
+		_, err := repo.CriarCategoria(context.Background(), entity.Categoria{Descricao: "Lanche"})
+		if !errors.Is(err, entity.ErrCategoriaDuplicada) {
+			t.Errorf("expected ErrCategoriaDuplicada, got %v", err)
+		}
+	})
+}
+
+func TestRecuperarCategorias(t *testing.T) {
+	db := setupCategoriaTestDB(t)
+	defer db.Close()
+
+	repo := &CategoriaDbMock{Db: db}
+
+	t.Run("Empty table returns an empty list", func(t *testing.T) {
+		categorias, err := repo.RecuperarCategorias(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if categorias == nil || len(categorias) != 0 {
+			t.Errorf("expected an empty, non-nil list, got %#v", categorias)
+		}
+	})
+
+	// Insert sample data
+	_, err := db.Exec(`INSERT INTO categoria_produtos (descricao) VALUES ('Lanche'), ('Bebida')`)
+	if err != nil {
+		t.Fatalf("failed to insert sample categorias: %v", err)
+	}
+
+	t.Run("Retrieve all categorias", func(t *testing.T) {
+		categorias, err := repo.RecuperarCategorias(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(categorias) != 2 || categorias[1].Descricao != "Bebida" {
+			t.Errorf("unexpected categorias: %+v", categorias)
+		}
+	})
+
+	t.Run("Retrieve categoria by ID", func(t *testing.T) {
+		categoria, err := repo.RecuperarCategoria(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if categoria.Descricao != "Lanche" {
+			t.Errorf("expected descricao 'Lanche', got '%s'", categoria.Descricao)
+		}
+
+		_, err = repo.RecuperarCategoria(context.Background(), 99)
+		if !errors.Is(err, entity.ErrCategoriaNaoEncontrada) {
+			t.Errorf("expected ErrCategoriaNaoEncontrada, got %v", err)
+		}
+	})
+}
+
+func TestAtualizarEDeletarCategoria(t *testing.T) {
+	db := setupCategoriaTestDB(t)
+	defer db.Close()
+
+	repo := &CategoriaDbMock{Db: db}
+
+	// Insert sample data
+	_, err := db.Exec(`INSERT INTO categoria_produtos (descricao) VALUES ('Lanche')`)
+	if err != nil {
+		t.Fatalf("failed to insert sample categoria: %v", err)
+	}
+
+	t.Run("Update categoria", func(t *testing.T) {
+		if err := repo.AtualizarCategoria(context.Background(), 1, entity.Categoria{Descricao: "Combo"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var descricao string
+		if err := db.QueryRow(`SELECT descricao FROM categoria_produtos WHERE id = 1`).Scan(&descricao); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if descricao != "Combo" {
+			t.Errorf("expected descricao 'Combo', got '%s'", descricao)
+		}
+
+		if err := repo.AtualizarCategoria(context.Background(), 99, entity.Categoria{Descricao: "Combo"}); !errors.Is(err, entity.ErrCategoriaNaoEncontrada) {
+			t.Errorf("expected ErrCategoriaNaoEncontrada, got %v", err)
+		}
+	})
+
+	t.Run("Reject update to a duplicated descricao", func(t *testing.T) {
+		if _, err := db.Exec(`INSERT INTO categoria_produtos (descricao) VALUES ('Bebida')`); err != nil {
+			t.Fatalf("failed to insert sample categoria: %v", err)
+		}
+		if err := repo.AtualizarCategoria(context.Background(), 2, entity.Categoria{Descricao: "Combo"}); !errors.Is(err, entity.ErrCategoriaDuplicada) {
+			t.Errorf("expected ErrCategoriaDuplicada, got %v", err)
+		}
+	})
+
+	t.Run("Reject deleting a categoria with produtos", func(t *testing.T) {
+		if _, err := db.Exec(`INSERT INTO produtos (categoria_id) VALUES (2)`); err != nil {
+			t.Fatalf("failed to insert sample produto: %v", err)
+		}
+		if err := repo.DeletarCategoria(context.Background(), 2); !errors.Is(err, entity.ErrCategoriaEmUso) {
+			t.Errorf("expected ErrCategoriaEmUso, got %v", err)
+		}
+
+		var categorias int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM categoria_produtos WHERE id = 2`).Scan(&categorias); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if categorias != 1 {
+			t.Errorf("expected categoria 2 to be kept, got %d rows", categorias)
+		}
+	})
+
+	t.Run("Delete categoria", func(t *testing.T) {
+		if err := repo.DeletarCategoria(context.Background(), 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := repo.DeletarCategoria(context.Background(), 1); !errors.Is(err, entity.ErrCategoriaNaoEncontrada) {
+			t.Errorf("expected ErrCategoriaNaoEncontrada, got %v", err)
+		}
+	})
+}
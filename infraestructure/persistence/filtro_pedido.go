@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+var ordenacoesPedidos = map[entity.OrdenacaoPedidos]string{
+	entity.OrdenarPorData:     "data, id",
+	entity.OrdenarPorDataDesc: "data DESC, id DESC",
+	entity.OrdenarPorId:       "id",
+	entity.OrdenarPorIdDesc:   "id DESC",
+}
+
+// consultaPedidos monta a listagem filtrada e paginada de pedidos. O filtro, a ordenação e
+// o LIMIT são aplicados aos pedidos antes do join com produto_pedido, para que o limite
+// conte pedidos e não itens; o LEFT JOIN mantém na página os pedidos sem itens. param
+// gera o placeholder do n-ésimo argumento no dialeto do banco ($1 no Postgres, ?1 no
+// SQLite).
+func consultaPedidos(f entity.FiltroPedidos, param func(n int) string) (string, []any) {
+	var condicoes []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return param(len(args))
+	}
+
+	if f.Status != "" {
+		condicoes = append(condicoes, "status = "+arg(f.Status))
+	}
+	if f.Cpf != 0 {
+		condicoes = append(condicoes, "cliente_cpf = "+arg(f.Cpf))
+	}
+	if f.PagamentoAprovado != nil {
+		condicoes = append(condicoes, "pagamento_aprovado = "+arg(*f.PagamentoAprovado))
+	}
+	if f.CriadoDe != nil {
+		condicoes = append(condicoes, "data >= "+arg(*f.CriadoDe))
+	}
+	if f.CriadoAte != nil {
+		condicoes = append(condicoes, "data <= "+arg(*f.CriadoAte))
+	}
+
+	ordenacao, ok := ordenacoesPedidos[f.Ordenacao]
+	if !ok {
+		ordenacao = ordenacoesPedidos[entity.OrdenarPorData]
+	}
+
+	if c := f.Cursor; c != nil {
+		comparacao := ">"
+		if c.Ordenacao.Decrescente() {
+			comparacao = "<"
+		}
+		switch c.Ordenacao {
+		case entity.OrdenarPorData, entity.OrdenarPorDataDesc:
+			data, id := arg(c.CriadoEm), arg(c.Id)
+			condicoes = append(condicoes, fmt.Sprintf("(data %s %s OR (data = %s AND id %s %s))", comparacao, data, data, comparacao, id))
+		default:
+			condicoes = append(condicoes, fmt.Sprintf("id %s %s", comparacao, arg(c.Id)))
+		}
+	}
+
+	var pagina strings.Builder
+	pagina.WriteString("SELECT id, cliente_cpf, status, metodo_pagamento, pagamento_aprovado, tempo_preparo_minutos, previsao_pronto, data FROM pedidos")
+	if len(condicoes) > 0 {
+		pagina.WriteString(" WHERE " + strings.Join(condicoes, " AND "))
+	}
+	pagina.WriteString(" ORDER BY " + ordenacao)
+	if f.Limite > 0 {
+		pagina.WriteString(" LIMIT " + arg(f.Limite))
+	}
+
+	return fmt.Sprintf(`
+        WITH pagina AS (%s)
+        SELECT
+            A.id,
+            A.cliente_cpf,
+            A.status,
+            A.metodo_pagamento,
+            A.pagamento_aprovado,
+            A.tempo_preparo_minutos,
+            A.previsao_pronto,
+            A.data,
+            B.id,
+            B.produto_id,
+            B.quantidade,
+            B.observacao,
+            B.preco_unitario_centavos
+        FROM pagina A
+        LEFT JOIN produto_pedido B ON A.id = B.pedido_id
+        ORDER BY %s, B.id;
+    `, pagina.String(), "A."+strings.ReplaceAll(ordenacao, ", ", ", A.")), args
+}
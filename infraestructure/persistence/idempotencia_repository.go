@@ -0,0 +1,76 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotenciaDbConnection struct {
+	Db *pgxpool.Pool
+}
+
+// RESERVAR_IDEMPOTENCIA só atualiza uma chave existente quando ela é uma reserva abandonada
+// da mesma requisição; nos demais casos nenhuma linha é afetada.
+const RESERVAR_IDEMPOTENCIA = `
+        INSERT INTO idempotencia (chave, hash_requisicao) VALUES ($1, $2)
+        ON CONFLICT (chave) DO UPDATE SET criado_em = NOW()
+        WHERE idempotencia.status_resposta IS NULL
+            AND idempotencia.hash_requisicao = EXCLUDED.hash_requisicao
+            AND idempotencia.criado_em < NOW() - make_interval(secs => $3);
+    `
+
+func (repo *IdempotenciaDbConnection) Reservar(ctx context.Context, chave string, hash string, abandono time.Duration) (entity.Idempotencia, bool, error) {
+	tag, err := repo.Db.Exec(ctx, RESERVAR_IDEMPOTENCIA, chave, hash, abandono.Seconds())
+	if err != nil {
+		fmt.Println("Erro ao reservar chave de idempotência:", err)
+		return entity.Idempotencia{}, false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return entity.Idempotencia{Chave: chave, Hash: hash}, true, nil
+	}
+
+	var (
+		i           = entity.Idempotencia{Chave: chave}
+		status      sql.NullInt32
+		contentType sql.NullString
+	)
+	err = repo.Db.QueryRow(ctx, "SELECT hash_requisicao, status_resposta, content_type, corpo_resposta FROM idempotencia WHERE chave = $1", chave).
+		Scan(&i.Hash, &status, &contentType, &i.Corpo)
+	if err != nil {
+		fmt.Println("Erro ao recuperar chave de idempotência:", err)
+		return i, false, err
+	}
+	i.Status = int(status.Int32)
+	i.ContentType = contentType.String
+	return i, false, nil
+}
+
+func (repo *IdempotenciaDbConnection) Concluir(ctx context.Context, chave string, status int, contentType string, corpo []byte) error {
+	_, err := repo.Db.Exec(ctx, "UPDATE idempotencia SET status_resposta = $1, content_type = $2, corpo_resposta = $3 WHERE chave = $4", status, contentType, corpo, chave)
+	if err != nil {
+		fmt.Println("Erro ao gravar resposta idempotente:", err)
+	}
+	return err
+}
+
+func (repo *IdempotenciaDbConnection) Liberar(ctx context.Context, chave string) error {
+	_, err := repo.Db.Exec(ctx, "DELETE FROM idempotencia WHERE chave = $1 AND status_resposta IS NULL", chave)
+	if err != nil {
+		fmt.Println("Erro ao liberar chave de idempotência:", err)
+	}
+	return err
+}
+
+func (repo *IdempotenciaDbConnection) Expirar(ctx context.Context, validade time.Duration) (int64, error) {
+	tag, err := repo.Db.Exec(ctx, "DELETE FROM idempotencia WHERE criado_em < NOW() - make_interval(secs => $1)", validade.Seconds())
+	if err != nil {
+		fmt.Println("Erro ao expirar chaves de idempotência:", err)
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
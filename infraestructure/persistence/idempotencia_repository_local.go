@@ -0,0 +1,77 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type IdempotenciaDbMock struct {
+	Db *sql.DB
+}
+
+const RESERVAR_IDEMPOTENCIA_SQLITE = `
+        INSERT INTO idempotencia (chave, hash_requisicao) VALUES (?1, ?2)
+        ON CONFLICT (chave) DO UPDATE SET criado_em = CURRENT_TIMESTAMP
+        WHERE status_resposta IS NULL
+            AND hash_requisicao = excluded.hash_requisicao
+            AND criado_em < datetime('now', ?3);
+    `
+
+func (repo *IdempotenciaDbMock) Reservar(ctx context.Context, chave string, hash string, abandono time.Duration) (entity.Idempotencia, bool, error) {
+	res, err := repo.Db.ExecContext(ctx, RESERVAR_IDEMPOTENCIA_SQLITE, chave, hash, modificadorSegundos(abandono))
+	if err != nil {
+		return entity.Idempotencia{}, false, fmt.Errorf("erro ao reservar chave de idempotência: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 1 {
+		return entity.Idempotencia{Chave: chave, Hash: hash}, true, nil
+	}
+
+	var (
+		i           = entity.Idempotencia{Chave: chave}
+		status      sql.NullInt32
+		contentType sql.NullString
+	)
+	err = repo.Db.QueryRowContext(ctx, "SELECT hash_requisicao, status_resposta, content_type, corpo_resposta FROM idempotencia WHERE chave = ?", chave).
+		Scan(&i.Hash, &status, &contentType, &i.Corpo)
+	if err != nil {
+		return i, false, fmt.Errorf("erro ao recuperar chave de idempotência: %w", err)
+	}
+	i.Status = int(status.Int32)
+	i.ContentType = contentType.String
+	return i, false, nil
+}
+
+func (repo *IdempotenciaDbMock) Concluir(ctx context.Context, chave string, status int, contentType string, corpo []byte) error {
+	_, err := repo.Db.ExecContext(ctx, "UPDATE idempotencia SET status_resposta = ?, content_type = ?, corpo_resposta = ? WHERE chave = ?", status, contentType, corpo, chave)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar resposta idempotente: %w", err)
+	}
+	return nil
+}
+
+func (repo *IdempotenciaDbMock) Liberar(ctx context.Context, chave string) error {
+	_, err := repo.Db.ExecContext(ctx, "DELETE FROM idempotencia WHERE chave = ? AND status_resposta IS NULL", chave)
+	if err != nil {
+		return fmt.Errorf("erro ao liberar chave de idempotência: %w", err)
+	}
+	return nil
+}
+
+func (repo *IdempotenciaDbMock) Expirar(ctx context.Context, validade time.Duration) (int64, error) {
+	res, err := repo.Db.ExecContext(ctx, "DELETE FROM idempotencia WHERE criado_em < datetime('now', ?)", modificadorSegundos(validade))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao expirar chaves de idempotência: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// modificadorSegundos escreve d no formato aceito por datetime('now', ...) para voltar no
+// tempo, comparável com os valores gravados por CURRENT_TIMESTAMP.
+func modificadorSegundos(d time.Duration) string {
+	return fmt.Sprintf("-%d seconds", int64(d.Seconds()))
+}
@@ -0,0 +1,133 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupIdempotenciaTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	_, err = db.Exec(`
+	CREATE TABLE idempotencia (
+		chave TEXT PRIMARY KEY,
+		hash_requisicao TEXT NOT NULL,
+		status_resposta INTEGER,
+		content_type TEXT,
+		corpo_resposta BLOB,
+		criado_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	`)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	return db
+}
+
+func TestIdempotencia(t *testing.T) {
+	db := setupIdempotenciaTestDB(t)
+	defer db.Close()
+
+	repo := &IdempotenciaDbMock{Db: db}
+	ctx := context.Background()
+
+	t.Run("Reserve a new key", func(t *testing.T) {
+		i, nova, err := repo.Reservar(ctx, "chave-1", "hash-1", time.Minute)
+		if err != nil || !nova || i.Concluida() {
+			t.Fatalf("expected a fresh reservation, got %+v, %v, %v", i, nova, err)
+		}
+	})
+
+	t.Run("Reserve a key in progress", func(t *testing.T) {
+		i, nova, err := repo.Reservar(ctx, "chave-1", "outro-hash", time.Minute)
+		if err != nil || nova || i.Hash != "hash-1" || i.Concluida() {
+			t.Fatalf("expected the pending reservation, got %+v, %v, %v", i, nova, err)
+		}
+	})
+
+	t.Run("Replay a completed key", func(t *testing.T) {
+		if err := repo.Concluir(ctx, "chave-1", 201, "application/json", []byte(`{"id":1}`)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		i, nova, err := repo.Reservar(ctx, "chave-1", "hash-1", time.Minute)
+		if err != nil || nova {
+			t.Fatalf("expected the stored key, got %v, %v", nova, err)
+		}
+		if i.Status != 201 || i.ContentType != "application/json" || string(i.Corpo) != `{"id":1}` {
+			t.Errorf("unexpected stored response: %+v", i)
+		}
+	})
+
+	t.Run("Release only pending keys", func(t *testing.T) {
+		if err := repo.Liberar(ctx, "chave-1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, nova, _ := repo.Reservar(ctx, "chave-1", "hash-1", time.Minute); nova {
+			t.Errorf("expected a completed key to survive Liberar")
+		}
+
+		repo.Reservar(ctx, "chave-2", "hash-2", time.Minute)
+		if err := repo.Liberar(ctx, "chave-2"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, nova, _ := repo.Reservar(ctx, "chave-2", "hash-3", time.Minute); !nova {
+			t.Errorf("expected a released key to be reservable again")
+		}
+	})
+
+	envelhecer := func(t *testing.T, chave string, idade string) {
+		t.Helper()
+		if _, err := db.Exec("UPDATE idempotencia SET criado_em = datetime('now', ?) WHERE chave = ?", idade, chave); err != nil {
+			t.Fatalf("failed to age key: %v", err)
+		}
+	}
+
+	t.Run("Take over an abandoned reservation", func(t *testing.T) {
+		repo.Reservar(ctx, "chave-3", "hash-3", time.Minute)
+		envelhecer(t, "chave-3", "-2 minutes")
+
+		if _, nova, err := repo.Reservar(ctx, "chave-3", "outro-hash", time.Minute); err != nil || nova {
+			t.Fatalf("expected another request not to take over the key, got %v, %v", nova, err)
+		}
+		if _, nova, err := repo.Reservar(ctx, "chave-3", "hash-3", time.Minute); err != nil || !nova {
+			t.Fatalf("expected the abandoned reservation to be taken over, got %v, %v", nova, err)
+		}
+		if _, nova, _ := repo.Reservar(ctx, "chave-3", "hash-3", time.Minute); nova {
+			t.Errorf("expected the renewed reservation to be in progress")
+		}
+	})
+
+	t.Run("Keep completed keys past the abandonment window", func(t *testing.T) {
+		envelhecer(t, "chave-1", "-2 minutes")
+		if i, nova, _ := repo.Reservar(ctx, "chave-1", "hash-1", time.Minute); nova || !i.Concluida() {
+			t.Errorf("expected the stored response, got %+v, %v", i, nova)
+		}
+	})
+
+	t.Run("Expire old keys", func(t *testing.T) {
+		envelhecer(t, "chave-1", "-2 days")
+
+		removidas, err := repo.Expirar(ctx, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if removidas != 1 {
+			t.Errorf("expected 1 expired key, got %d", removidas)
+		}
+		if _, nova, _ := repo.Reservar(ctx, "chave-1", "hash-1", time.Minute); !nova {
+			t.Errorf("expected an expired key to be reservable again")
+		}
+		if _, nova, _ := repo.Reservar(ctx, "chave-3", "hash-3", time.Minute); nova {
+			t.Errorf("expected a recent key to survive Expirar")
+		}
+	})
+}
@@ -1,17 +1,56 @@
 package persistence
 
-import "github.com/gomesmatheus/tc-pedido/domain/entity"
+import (
+	"context"
+	"time"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
 
 type ProdutoRepository interface {
-	CriarProduto(p entity.Produto) (entity.Produto, error)
-	RecuperarProdutos(categoriaId int) ([]entity.Produto, error)
-	AtualizarProduto(id int, p entity.Produto) error
-	DeletarProduto(id int) error
+	CriarProduto(ctx context.Context, p entity.Produto) (entity.Produto, error)
+	RecuperarProdutos(ctx context.Context, categoriaId int) ([]entity.Produto, error)
+	RecuperarTodosProdutos(ctx context.Context) ([]entity.Produto, error)
+	RecuperarProduto(ctx context.Context, id int) (entity.Produto, error)
+	AtualizarProduto(ctx context.Context, id int, p entity.Produto) error
+	DeletarProduto(ctx context.Context, id int) error
+}
+
+type CategoriaRepository interface {
+	CriarCategoria(ctx context.Context, c entity.Categoria) (entity.Categoria, error)
+	RecuperarCategorias(ctx context.Context) ([]entity.Categoria, error)
+	RecuperarCategoria(ctx context.Context, id int) (entity.Categoria, error)
+	AtualizarCategoria(ctx context.Context, id int, c entity.Categoria) error
+	DeletarCategoria(ctx context.Context, id int) error
 }
 
+// PedidoRepository grava as alterações de pedidos. AtualizarPagamento devolve false, sem
+// registrar nada, quando a mesma notificação de pagamento já tinha sido aplicada.
 type PedidoRepository interface {
-	CriarPedido(entity.Pedido) (entity.Pedido, error)
-	RecuperarPedidos() ([]entity.Pedido, error)
-	AtualizarStatus(id int, status string) error
-	AtualizarPagamento(id int, status bool) error
+	CriarPedido(ctx context.Context, p entity.Pedido) (entity.Pedido, error)
+	RecuperarPedidos(ctx context.Context, filtro entity.FiltroPedidos) ([]entity.Pedido, error)
+	RecuperarPedido(ctx context.Context, id int) (entity.Pedido, error)
+	RecuperarFilaCozinha(ctx context.Context) ([]entity.Pedido, error)
+	RecuperarStatus(ctx context.Context, id int) (entity.StatusPedido, error)
+	AtualizarStatus(ctx context.Context, id int, statusAtual entity.StatusPedido, status entity.StatusPedido, responsavel string) (entity.HistoricoPedido, error)
+	RecuperarPagamento(ctx context.Context, id int) (bool, error)
+	AtualizarPagamento(ctx context.Context, id int, status bool, responsavel string) (entity.HistoricoPedido, bool, error)
+	RecuperarFilaPreparo(ctx context.Context) ([]entity.Pedido, error)
+	AtualizarPrevisoes(ctx context.Context, fila []entity.Pedido) error
+	CancelarPedido(ctx context.Context, c entity.Cancelamento, statusAtual entity.StatusPedido) error
+	AtualizarReembolso(ctx context.Context, id int, status entity.StatusReembolso) error
+	AprovarPagamentoCancelado(ctx context.Context, id int, valor entity.Dinheiro, responsavel string) (entity.HistoricoPedido, entity.SolicitacaoReembolso, error)
+	RecuperarReembolsosPendentes(ctx context.Context) ([]entity.SolicitacaoReembolso, error)
+	RecuperarHistorico(ctx context.Context, id int) ([]entity.HistoricoPedido, error)
+}
+
+// IdempotenciaRepository guarda as respostas de requisições feitas com Idempotency-Key.
+// Reservar devolve o registro já existente e false quando a chave já tinha sido usada; uma
+// reserva do mesmo hash sem resposta há mais de abandono é assumida e devolve true.
+// Expirar apaga as chaves criadas há mais de validade.
+type IdempotenciaRepository interface {
+	Reservar(ctx context.Context, chave string, hash string, abandono time.Duration) (entity.Idempotencia, bool, error)
+	Concluir(ctx context.Context, chave string, status int, contentType string, corpo []byte) error
+	Liberar(ctx context.Context, chave string) error
+	Expirar(ctx context.Context, validade time.Duration) (int64, error)
 }
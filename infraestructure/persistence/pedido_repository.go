@@ -2,88 +2,593 @@ package persistence
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/gomesmatheus/tc-pedido/domain/entity"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type PedidoDbConnection struct {
-	Db *pgx.Conn
+	Db *pgxpool.Pool
 }
 
 type PedidoRow struct {
 	Id                int
 	Cpf               int64
-	Status            string
+	Status            entity.StatusPedido
 	MetodoPagamento   string
 	PagamentoAprovado bool
-	ProdutoId         int
-	Quantidade        int
-	Observacao        string
+	TempoDePreparo    int
+	PrevisaoPronto    *time.Time
+	CriadoEm          time.Time
+	// As colunas do item ficam nulas para um pedido sem itens, devolvido pelo LEFT JOIN.
+	ItemId        *int
+	ProdutoId     *int
+	Quantidade    *int
+	Observacao    *string
+	PrecoUnitario *int64
 }
 
 const (
-	QUERY_PEDIDOS = `
+	QUERY_FILA_PREPARO = `
+        SELECT id, status, tempo_preparo_minutos, previsao_pronto
+        FROM pedidos
+        WHERE status IN ($1, $2)
+        ORDER BY data, id;
+    `
+
+	QUERY_FILA_COZINHA = `
+        SELECT
+            A.id,
+            A.cliente_cpf,
+            A.status,
+            A.metodo_pagamento,
+            A.pagamento_aprovado,
+            A.tempo_preparo_minutos,
+            A.previsao_pronto,
+            A.data,
+            B.id,
+            B.produto_id,
+            B.quantidade,
+            B.observacao,
+            B.preco_unitario_centavos
+        FROM pedidos A
+        INNER JOIN produto_pedido B ON A.id = B.pedido_id
+        WHERE A.status IN ($1, $2, $3)
+        ORDER BY
+            CASE A.status WHEN $1 THEN 0 WHEN $2 THEN 1 ELSE 2 END,
+            A.data,
+            A.id,
+            B.id;
+    `
+
+	QUERY_PEDIDO = `
         SELECT
             A.id,
             A.cliente_cpf,
             A.status,
             A.metodo_pagamento,
-						A.pagamento_aprovado,
+            A.pagamento_aprovado,
+            A.tempo_preparo_minutos,
+            A.previsao_pronto,
+            A.data,
+            B.id,
             B.produto_id,
             B.quantidade,
-            B.observacao
+            B.observacao,
+            B.preco_unitario_centavos
         FROM pedidos A
-        INNER JOIN produto_pedido B ON A.id = B.pedido_id;
+        LEFT JOIN produto_pedido B ON A.id = B.pedido_id
+        WHERE A.id = $1
+        ORDER BY B.id;
+    `
+
+	QUERY_REEMBOLSOS_PENDENTES = `
+        SELECT pedido_id, reembolso_centavos, motivo
+        FROM cancelamento_pedido
+        WHERE reembolso_status = $1
+        ORDER BY cancelado_em, pedido_id;
+    `
+
+	// UPDATE_PAGAMENTO só altera o pedido quando o resultado muda. Uma recusa não muda a
+	// coluna de um pedido novo, então a falta de entrada de pagamento no histórico é o que
+	// separa a primeira notificação de uma reentrega.
+	UPDATE_PAGAMENTO = `
+        UPDATE pedidos SET pagamento_aprovado = $1
+        WHERE id = $2
+            AND (pagamento_aprovado IS DISTINCT FROM $1
+                OR NOT EXISTS (SELECT 1 FROM pedido_status_historico WHERE pedido_id = $2 AND evento = $3));
+    `
+
+	QUERY_HISTORICO = `
+        SELECT evento, status, pagamento_aprovado, responsavel, motivo, registrado_em
+        FROM pedido_status_historico
+        WHERE pedido_id = $1
+        ORDER BY registrado_em, id;
+    `
+
+	// INSERT_HISTORICO copia o status e o pagamento do pedido como estão dentro da
+	// transação, depois da alteração que está sendo registrada.
+	INSERT_HISTORICO = `
+        INSERT INTO pedido_status_historico (pedido_id, evento, status, pagamento_aprovado, responsavel, motivo, registrado_em)
+        SELECT id, $2, status, pagamento_aprovado, $3, $4, $5
+        FROM pedidos
+        WHERE id = $1
+        RETURNING status, pagamento_aprovado;
     `
 )
 
-func (repo *PedidoDbConnection) CriarPedido(p entity.Pedido) (entity.Pedido, error) {
+// CriarPedido grava o pedido, seus itens e a primeira entrada do histórico em uma única
+// transação; os itens são enviados em um batch. Qualquer falha desfaz a transação, sem
+// deixar pedido sem itens na base.
+func (repo *PedidoDbConnection) CriarPedido(ctx context.Context, p entity.Pedido) (entity.Pedido, error) {
+	tx, err := repo.Db.Begin(ctx)
+	if err != nil {
+		fmt.Println("Erro ao iniciar transação do pedido:", err)
+		return p, err
+	}
+	defer tx.Rollback(ctx)
+
 	var idPedido int
-	err := repo.Db.QueryRow(context.Background(), "INSERT INTO pedidos (cliente_cpf, status, data, metodo_pagamento) VALUES ($1, $2, $3, $4) RETURNING id", p.Cpf, "Recebido", time.Now(), p.MetodoPagamento).Scan(&idPedido)
+	criadoEm := time.Now()
+	err = tx.QueryRow(ctx, "INSERT INTO pedidos (cliente_cpf, status, data, metodo_pagamento, tempo_preparo_minutos) VALUES ($1, $2, $3, $4, $5) RETURNING id", p.Cpf, entity.StatusRecebido, criadoEm, p.MetodoPagamento, p.TempoDePreparo).Scan(&idPedido)
 	if err != nil {
 		fmt.Println("Erro ao inserir pedido na base de dados", err)
+		return p, err
 	}
 
+	batch := &pgx.Batch{}
 	for _, pp := range p.Produtos {
-		_, err := repo.Db.Exec(context.Background(), "INSERT INTO produto_pedido (produto_id, pedido_id, quantidade, observacao) values ($1, $2, $3, $4)", pp.ProdutoId, idPedido, pp.Quantidade, pp.Observacao)
-		if err != nil {
-			fmt.Println("Erro ao inserir pedido na base de dados", err)
-			return p, err
+		batch.Queue("INSERT INTO produto_pedido (produto_id, pedido_id, quantidade, observacao, preco_unitario_centavos) VALUES ($1, $2, $3, $4, $5) RETURNING id", pp.ProdutoId, idPedido, pp.Quantidade, pp.Observacao, pp.PrecoUnitario.Centavos)
+	}
+	resultados := tx.SendBatch(ctx, batch)
+	for i := range p.Produtos {
+		if err = resultados.QueryRow().Scan(&p.Produtos[i].Id); err != nil {
+			break
 		}
 	}
+	if errClose := resultados.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		fmt.Println("Erro ao inserir itens do pedido na base de dados", err)
+		return p, err
+	}
+
+	if _, err = inserirHistorico(ctx, tx, idPedido, entity.EventoCriacao, entity.ResponsavelCliente, "", criadoEm); err != nil {
+		return p, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		fmt.Println("Erro ao confirmar transação do pedido:", err)
+		return p, err
+	}
 
 	p.Id = idPedido
-	return p, err
+	p.Status = entity.StatusRecebido
+	p.CriadoEm = criadoEm
+	if err = p.CalcularTotal(); err != nil {
+		return p, err
+	}
+	return p, nil
 }
 
-func (repo *PedidoDbConnection) RecuperarPedidos() ([]entity.Pedido, error) {
-	var pedidos []entity.Pedido
-	rows, err := repo.Db.Query(context.Background(), QUERY_PEDIDOS)
+func (repo *PedidoDbConnection) RecuperarPedidos(ctx context.Context, filtro entity.FiltroPedidos) ([]entity.Pedido, error) {
+	query, args := consultaPedidos(filtro, func(n int) string { return fmt.Sprintf("$%d", n) })
+	rows, err := repo.Db.Query(ctx, query, args...)
 	if err != nil {
 		fmt.Println("Erro ao recuperar pedidos:", err)
 		return nil, err
 	}
 	defer rows.Close()
 
+	pedidos, err := lerPedidos(rows)
+	if err != nil {
+		fmt.Println("Erro fazendo scanning de pedido:", err)
+		return nil, err
+	}
+
+	return pedidos, nil
+}
+
+func (repo *PedidoDbConnection) RecuperarPedido(ctx context.Context, idPedido int) (entity.Pedido, error) {
+	rows, err := repo.Db.Query(ctx, QUERY_PEDIDO, idPedido)
+	if err != nil {
+		fmt.Println("Erro ao recuperar pedido:", err)
+		return entity.Pedido{}, err
+	}
+	defer rows.Close()
+
+	pedidos, err := lerPedidos(rows)
+	if err != nil {
+		fmt.Println("Erro fazendo scanning de pedido:", err)
+		return entity.Pedido{}, err
+	}
+	if len(pedidos) == 0 {
+		return entity.Pedido{}, entity.ErrPedidoNaoEncontrado
+	}
+
+	return pedidos[0], nil
+}
+
+// RecuperarFilaCozinha lista os pedidos ativos na ordem de atendimento da cozinha:
+// prontos, em preparação e recebidos, do mais antigo para o mais novo em cada grupo.
+func (repo *PedidoDbConnection) RecuperarFilaCozinha(ctx context.Context) ([]entity.Pedido, error) {
+	rows, err := repo.Db.Query(ctx, QUERY_FILA_COZINHA, entity.StatusPronto, entity.StatusEmPreparacao, entity.StatusRecebido)
+	if err != nil {
+		fmt.Println("Erro ao recuperar fila da cozinha:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	pedidos, err := lerPedidos(rows)
+	if err != nil {
+		fmt.Println("Erro fazendo scanning de pedido:", err)
+		return nil, err
+	}
+
+	return pedidos, nil
+}
+
+func (repo *PedidoDbConnection) RecuperarStatus(ctx context.Context, idPedido int) (entity.StatusPedido, error) {
+	var status entity.StatusPedido
+	err := repo.Db.QueryRow(ctx, "SELECT status FROM pedidos WHERE id = $1", idPedido).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", entity.ErrPedidoNaoEncontrado
+	}
+	if err != nil {
+		fmt.Println("Erro ao recuperar status do pedido na base de dados", err)
+	}
+	return status, err
+}
+
+// AtualizarStatus troca o status e registra a alteração no histórico na mesma transação,
+// devolvendo a entrada registrada. Assim como em CancelarPedido, o status só é alterado se
+// ainda for statusAtual, para que duas atualizações concorrentes não produzam uma transição
+// que não foi validada.
+func (repo *PedidoDbConnection) AtualizarStatus(ctx context.Context, idPedido int, statusAtual entity.StatusPedido, status entity.StatusPedido, responsavel string) (entity.HistoricoPedido, error) {
+	if !status.Valido() {
+		return entity.HistoricoPedido{}, entity.ErrStatusInvalido
+	}
+	tx, err := repo.Db.Begin(ctx)
+	if err != nil {
+		fmt.Println("Erro ao iniciar transação do status do pedido:", err)
+		return entity.HistoricoPedido{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "UPDATE pedidos SET status = $1 WHERE id = $2 AND status = $3", status, idPedido, statusAtual)
+	if err != nil {
+		fmt.Println("Erro ao trocar status do pedido na base de dados", err)
+		return entity.HistoricoPedido{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.HistoricoPedido{}, statusAlterado(tx.QueryRow(ctx, "SELECT status FROM pedidos WHERE id = $1", idPedido))
+	}
+	h, err := inserirHistorico(ctx, tx, idPedido, entity.EventoStatus, responsavel, "", time.Now())
+	if err != nil {
+		return h, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		fmt.Println("Erro ao confirmar transação do status do pedido:", err)
+	}
+	return h, err
+}
+
+func (repo *PedidoDbConnection) RecuperarPagamento(ctx context.Context, idPedido int) (bool, error) {
+	var pagamentoAprovado bool
+	err := repo.Db.QueryRow(ctx, "SELECT pagamento_aprovado FROM pedidos WHERE id = $1", idPedido).Scan(&pagamentoAprovado)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, entity.ErrPedidoNaoEncontrado
+	}
+	if err != nil {
+		fmt.Println("Erro ao recuperar status do pagamento na base de dados", err)
+	}
+	return pagamentoAprovado, err
+}
+
+func (repo *PedidoDbConnection) AtualizarPagamento(ctx context.Context, idPedido int, pagamentoAprovado bool, responsavel string) (entity.HistoricoPedido, bool, error) {
+	tx, err := repo.Db.Begin(ctx)
+	if err != nil {
+		fmt.Println("Erro ao iniciar transação do pagamento do pedido:", err)
+		return entity.HistoricoPedido{}, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, UPDATE_PAGAMENTO, pagamentoAprovado, idPedido, entity.EventoPagamento)
+	if err != nil {
+		fmt.Println("Erro ao trocar status do pagamento na base de dados", err)
+		return entity.HistoricoPedido{}, false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.HistoricoPedido{}, false, nil
+	}
+	h, err := inserirHistorico(ctx, tx, idPedido, entity.EventoPagamento, responsavel, "", time.Now())
+	if err != nil {
+		return h, false, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		fmt.Println("Erro ao confirmar transação do pagamento do pedido:", err)
+		return h, false, err
+	}
+	return h, true, nil
+}
+
+func (repo *PedidoDbConnection) RecuperarFilaPreparo(ctx context.Context) ([]entity.Pedido, error) {
+	var fila []entity.Pedido
+	rows, err := repo.Db.Query(ctx, QUERY_FILA_PREPARO, entity.StatusRecebido, entity.StatusEmPreparacao)
+	if err != nil {
+		fmt.Println("Erro ao recuperar fila de preparo:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p entity.Pedido
+		if err = rows.Scan(&p.Id, &p.Status, &p.TempoDePreparo, &p.PrevisaoPronto); err != nil {
+			fmt.Println("Erro fazendo scanning da fila de preparo:", err)
+			return nil, err
+		}
+		fila = append(fila, p)
+	}
+
+	return fila, rows.Err()
+}
+
+// AtualizarPrevisoes grava a previsão de todos os pedidos da fila em uma única transação,
+// enviada em um batch, para que a fila nunca fique com previsões de cálculos diferentes.
+func (repo *PedidoDbConnection) AtualizarPrevisoes(ctx context.Context, fila []entity.Pedido) error {
+	tx, err := repo.Db.Begin(ctx)
+	if err != nil {
+		fmt.Println("Erro ao iniciar transação das previsões:", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, p := range fila {
+		batch.Queue("UPDATE pedidos SET previsao_pronto = $1 WHERE id = $2", p.PrevisaoPronto, p.Id)
+	}
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		fmt.Println("Erro ao atualizar previsões dos pedidos na base de dados", err)
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		fmt.Println("Erro ao confirmar transação das previsões:", err)
+		return err
+	}
+	return nil
+}
+
+// CancelarPedido troca o status para Cancelado e grava o cancelamento na mesma transação.
+// O status só é alterado se ainda for statusAtual, para que um pedido que avançou na
+// cozinha depois de consultado não seja cancelado.
+func (repo *PedidoDbConnection) CancelarPedido(ctx context.Context, c entity.Cancelamento, statusAtual entity.StatusPedido) error {
+	tx, err := repo.Db.Begin(ctx)
+	if err != nil {
+		fmt.Println("Erro ao iniciar transação do cancelamento:", err)
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "UPDATE pedidos SET status = $1, previsao_pronto = NULL WHERE id = $2 AND status = $3", entity.StatusCancelado, c.PedidoId, statusAtual)
+	if err != nil {
+		fmt.Println("Erro ao cancelar pedido na base de dados", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: status do pedido foi alterado", entity.ErrCancelamentoNaoPermitido)
+	}
+
+	valor, status := colunasReembolso(c.Reembolso)
+	_, err = tx.Exec(ctx, "INSERT INTO cancelamento_pedido (pedido_id, motivo, cancelado_por, cancelado_em, reembolso_centavos, reembolso_status) VALUES ($1, $2, $3, $4, $5, $6)", c.PedidoId, c.Motivo, c.CanceladoPor, c.CanceladoEm, valor, status)
+	if err != nil {
+		fmt.Println("Erro ao registrar cancelamento na base de dados", err)
+		return err
+	}
+	if _, err = inserirHistorico(ctx, tx, c.PedidoId, entity.EventoCancelamento, c.CanceladoPor, c.Motivo, c.CanceladoEm); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		fmt.Println("Erro ao confirmar transação do cancelamento:", err)
+	}
+	return err
+}
+
+func (repo *PedidoDbConnection) AtualizarReembolso(ctx context.Context, idPedido int, status entity.StatusReembolso) error {
+	_, err := repo.Db.Exec(ctx, "UPDATE cancelamento_pedido SET reembolso_status = $1 WHERE pedido_id = $2", status, idPedido)
+	if err != nil {
+		fmt.Println("Erro ao atualizar reembolso na base de dados", err)
+	}
+	return err
+}
+
+// AprovarPagamentoCancelado grava um pagamento aprovado depois do cancelamento do pedido e
+// deixa pendente o reembolso de valor, na mesma transação. Devolve a solicitação a enviar ao
+// serviço de pagamentos, com o motivo do cancelamento.
+func (repo *PedidoDbConnection) AprovarPagamentoCancelado(ctx context.Context, idPedido int, valor entity.Dinheiro, responsavel string) (entity.HistoricoPedido, entity.SolicitacaoReembolso, error) {
+	s := entity.SolicitacaoReembolso{PedidoId: idPedido, Valor: valor}
+	tx, err := repo.Db.Begin(ctx)
+	if err != nil {
+		fmt.Println("Erro ao iniciar transação do pagamento do pedido:", err)
+		return entity.HistoricoPedido{}, s, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "UPDATE pedidos SET pagamento_aprovado = TRUE WHERE id = $1 AND status = $2 AND NOT pagamento_aprovado", idPedido, entity.StatusCancelado)
+	if err != nil {
+		fmt.Println("Erro ao trocar status do pagamento na base de dados", err)
+		return entity.HistoricoPedido{}, s, err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.HistoricoPedido{}, s, fmt.Errorf("%w: pedido não está cancelado aguardando pagamento", entity.ErrPagamentoJaProcessado)
+	}
+
+	err = tx.QueryRow(ctx, "UPDATE cancelamento_pedido SET reembolso_centavos = $1, reembolso_status = $2 WHERE pedido_id = $3 RETURNING motivo", valor.Centavos, entity.ReembolsoPendente, idPedido).Scan(&s.Motivo)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entity.HistoricoPedido{}, s, fmt.Errorf("%w: cancelamento do pedido não registrado", entity.ErrPedidoNaoEncontrado)
+	}
+	if err != nil {
+		fmt.Println("Erro ao registrar reembolso na base de dados", err)
+		return entity.HistoricoPedido{}, s, err
+	}
+	h, err := inserirHistorico(ctx, tx, idPedido, entity.EventoPagamento, responsavel, "", time.Now())
+	if err != nil {
+		return h, s, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		fmt.Println("Erro ao confirmar transação do pagamento do pedido:", err)
+	}
+	return h, s, err
+}
+
+// RecuperarReembolsosPendentes lista os reembolsos que ainda não foram aceitos pelo serviço
+// de pagamentos, dos cancelamentos mais antigos para os mais recentes.
+func (repo *PedidoDbConnection) RecuperarReembolsosPendentes(ctx context.Context) ([]entity.SolicitacaoReembolso, error) {
+	rows, err := repo.Db.Query(ctx, QUERY_REEMBOLSOS_PENDENTES, entity.ReembolsoPendente)
+	if err != nil {
+		fmt.Println("Erro ao recuperar reembolsos pendentes:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	solicitacoes, err := lerSolicitacoesReembolso(rows)
+	if err != nil {
+		fmt.Println("Erro fazendo scanning dos reembolsos pendentes:", err)
+		return nil, err
+	}
+	return solicitacoes, nil
+}
+
+// RecuperarHistorico lista a linha do tempo do pedido, da entrada mais antiga para a mais
+// recente. Todo pedido tem ao menos uma entrada, então uma lista vazia indica pedido inexistente.
+func (repo *PedidoDbConnection) RecuperarHistorico(ctx context.Context, idPedido int) ([]entity.HistoricoPedido, error) {
+	rows, err := repo.Db.Query(ctx, QUERY_HISTORICO, idPedido)
+	if err != nil {
+		fmt.Println("Erro ao recuperar histórico do pedido:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	historico, err := lerHistorico(rows)
+	if err != nil {
+		fmt.Println("Erro fazendo scanning do histórico do pedido:", err)
+		return nil, err
+	}
+	if len(historico) == 0 {
+		return nil, entity.ErrPedidoNaoEncontrado
+	}
+
+	return historico, nil
+}
+
+// inserirHistorico não encontra linha para copiar quando o pedido não existe.
+func inserirHistorico(ctx context.Context, tx pgx.Tx, idPedido int, evento entity.EventoPedido, responsavel string, motivo string, registradoEm time.Time) (entity.HistoricoPedido, error) {
+	h := entity.HistoricoPedido{Evento: evento, Responsavel: responsavel, Motivo: motivo, RegistradoEm: registradoEm}
+	err := tx.QueryRow(ctx, INSERT_HISTORICO, idPedido, evento, responsavel, motivo, registradoEm).Scan(&h.Status, &h.PagamentoAprovado)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return h, entity.ErrPedidoNaoEncontrado
+	}
+	if err != nil {
+		fmt.Println("Erro ao registrar histórico do pedido na base de dados", err)
+	}
+	return h, err
+}
+
+// statusAlterado explica por que a troca de status não alterou nenhuma linha: o pedido não
+// existe ou já saiu do status em que foi validado.
+func statusAlterado(row interface{ Scan(dest ...any) error }) error {
+	var status entity.StatusPedido
+	err := row.Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+		return entity.ErrPedidoNaoEncontrado
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: status do pedido foi alterado para %q", entity.ErrTransicaoStatusInvalida, status)
+}
+
+// colunasReembolso converte o reembolso para colunas que aceitam NULL quando não há reembolso.
+func colunasReembolso(r *entity.Reembolso) (*int64, *entity.StatusReembolso) {
+	if r == nil {
+		return nil, nil
+	}
+	return &r.Valor.Centavos, &r.Status
+}
+
+type linhasPedido interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+}
+
+func lerSolicitacoesReembolso(rows linhasPedido) ([]entity.SolicitacaoReembolso, error) {
+	var solicitacoes []entity.SolicitacaoReembolso
+	for rows.Next() {
+		var s entity.SolicitacaoReembolso
+		var centavos int64
+		if err := rows.Scan(&s.PedidoId, &centavos, &s.Motivo); err != nil {
+			return nil, err
+		}
+		s.Valor = entity.Centavos(centavos)
+		solicitacoes = append(solicitacoes, s)
+	}
+	return solicitacoes, rows.Err()
+}
+
+func lerHistorico(rows linhasPedido) ([]entity.HistoricoPedido, error) {
+	var historico []entity.HistoricoPedido
+	for rows.Next() {
+		var h entity.HistoricoPedido
+		if err := rows.Scan(&h.Evento, &h.Status, &h.PagamentoAprovado, &h.Responsavel, &h.Motivo, &h.RegistradoEm); err != nil {
+			return nil, err
+		}
+		historico = append(historico, h)
+	}
+	return historico, rows.Err()
+}
+
+// lerPedidos agrupa as linhas do join entre pedidos e produto_pedido, mantendo a ordem em
+// que cada pedido aparece no resultado da consulta. Um pedido sem itens vem do LEFT JOIN
+// com as colunas do item nulas e fica com a lista de produtos vazia.
+func lerPedidos(rows linhasPedido) ([]entity.Pedido, error) {
+	var pedidos []entity.Pedido
 	for rows.Next() {
 		var r PedidoRow
-		if err = rows.Scan(&r.Id, &r.Cpf, &r.Status, &r.MetodoPagamento, &r.PagamentoAprovado, &r.ProdutoId, &r.Quantidade, &r.Observacao); err != nil {
-			fmt.Println("Erro fazendo scanning de pedido:", err)
+		if err := rows.Scan(&r.Id, &r.Cpf, &r.Status, &r.MetodoPagamento, &r.PagamentoAprovado, &r.TempoDePreparo, &r.PrevisaoPronto, &r.CriadoEm, &r.ItemId, &r.ProdutoId, &r.Quantidade, &r.Observacao, &r.PrecoUnitario); err != nil {
 			return nil, err
 		}
 
+		var itens []entity.ProdutoPedido
+		if r.ItemId != nil {
+			item := entity.ProdutoPedido{
+				Id:            *r.ItemId,
+				ProdutoId:     *r.ProdutoId,
+				Quantidade:    *r.Quantidade,
+				PrecoUnitario: entity.Centavos(*r.PrecoUnitario),
+			}
+			if r.Observacao != nil {
+				item.Observacao = *r.Observacao
+			}
+			itens = append(itens, item)
+		}
+
 		pedidoJaExiste := false
-		for i, p := range pedidos {
-			if p.Id == r.Id {
+		for i := range pedidos {
+			if pedidos[i].Id == r.Id {
 				pedidoJaExiste = true
-				pedidos[i].Produtos = append(p.Produtos, entity.ProdutoPedido{
-					ProdutoId:  r.ProdutoId,
-					Quantidade: r.Quantidade,
-					Observacao: r.Observacao,
-				})
+				pedidos[i].Produtos = append(pedidos[i].Produtos, itens...)
+				break
 			}
 		}
 
@@ -94,32 +599,23 @@ func (repo *PedidoDbConnection) RecuperarPedidos() ([]entity.Pedido, error) {
 				Status:            r.Status,
 				MetodoPagamento:   r.MetodoPagamento,
 				PagamentoAprovado: r.PagamentoAprovado,
-				Produtos: []entity.ProdutoPedido{
-					{
-						ProdutoId:  r.ProdutoId,
-						Quantidade: r.Quantidade,
-						Observacao: r.Observacao,
-					},
-				},
+				TempoDePreparo:    r.TempoDePreparo,
+				PrevisaoPronto:    r.PrevisaoPronto,
+				CriadoEm:          r.CriadoEm,
+				Produtos:          append([]entity.ProdutoPedido{}, itens...),
 			})
 		}
 	}
 
-	return pedidos, err
-}
-
-func (repo *PedidoDbConnection) AtualizarStatus(idPedido int, status string) error {
-	_, err := repo.Db.Exec(context.Background(), "UPDATE pedidos SET status = $1 WHERE id = $2", status, idPedido)
-	if err != nil {
-		fmt.Println("Erro ao trocar status do pedido na base de dados", err)
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
-	return err
-}
 
-func (repo *PedidoDbConnection) AtualizarPagamento(idPedido int, pagamentoAprovado bool) error {
-	_, err := repo.Db.Exec(context.Background(), "UPDATE pedidos SET pagamento_aprovado = $1 WHERE id = $2", pagamentoAprovado, idPedido)
-	if err != nil {
-		fmt.Println("Erro ao trocar status do pagamento na base de dados", err)
+	for i := range pedidos {
+		if err := pedidos[i].CalcularTotal(); err != nil {
+			return nil, err
+		}
 	}
-	return err
+
+	return pedidos, nil
 }
@@ -1,7 +1,9 @@
 package persistence
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -14,114 +16,441 @@ type PedidoDbMock struct {
 }
 
 const (
-	QUERY_PEDIDOS_SQLITE = `
+	QUERY_FILA_PREPARO_SQLITE = `
+        SELECT id, status, tempo_preparo_minutos, previsao_pronto
+        FROM pedidos
+        WHERE status IN (?, ?)
+        ORDER BY data, id;
+    `
+
+	QUERY_FILA_COZINHA_SQLITE = `
         SELECT
             A.id,
             A.cliente_cpf,
             A.status,
             A.metodo_pagamento,
             A.pagamento_aprovado,
+            A.tempo_preparo_minutos,
+            A.previsao_pronto,
+            A.data,
+            B.id,
             B.produto_id,
             B.quantidade,
-            B.observacao
+            B.observacao,
+            B.preco_unitario_centavos
         FROM pedidos A
-        INNER JOIN produto_pedido B ON A.id = B.pedido_id;
+        INNER JOIN produto_pedido B ON A.id = B.pedido_id
+        WHERE A.status IN (?1, ?2, ?3)
+        ORDER BY
+            CASE A.status WHEN ?1 THEN 0 WHEN ?2 THEN 1 ELSE 2 END,
+            A.data,
+            A.id,
+            B.id;
+    `
+
+	QUERY_PEDIDO_SQLITE = `
+        SELECT
+            A.id,
+            A.cliente_cpf,
+            A.status,
+            A.metodo_pagamento,
+            A.pagamento_aprovado,
+            A.tempo_preparo_minutos,
+            A.previsao_pronto,
+            A.data,
+            B.id,
+            B.produto_id,
+            B.quantidade,
+            B.observacao,
+            B.preco_unitario_centavos
+        FROM pedidos A
+        LEFT JOIN produto_pedido B ON A.id = B.pedido_id
+        WHERE A.id = ?
+        ORDER BY B.id;
+    `
+
+	QUERY_REEMBOLSOS_PENDENTES_SQLITE = `
+        SELECT pedido_id, reembolso_centavos, motivo
+        FROM cancelamento_pedido
+        WHERE reembolso_status = ?
+        ORDER BY cancelado_em, pedido_id;
+    `
+
+	UPDATE_PAGAMENTO_SQLITE = `
+        UPDATE pedidos SET pagamento_aprovado = ?1
+        WHERE id = ?2
+            AND (pagamento_aprovado IS NOT ?1
+                OR NOT EXISTS (SELECT 1 FROM pedido_status_historico WHERE pedido_id = ?2 AND evento = ?3));
+    `
+
+	QUERY_HISTORICO_SQLITE = `
+        SELECT evento, status, pagamento_aprovado, responsavel, motivo, registrado_em
+        FROM pedido_status_historico
+        WHERE pedido_id = ?
+        ORDER BY registrado_em, id;
+    `
+
+	INSERT_HISTORICO_SQLITE = `
+        INSERT INTO pedido_status_historico (pedido_id, evento, status, pagamento_aprovado, responsavel, motivo, registrado_em)
+        SELECT id, ?2, status, pagamento_aprovado, ?3, ?4, ?5
+        FROM pedidos
+        WHERE id = ?1
+        RETURNING status, pagamento_aprovado;
     `
 )
 
-func (repo *PedidoDbMock) CriarPedido(p entity.Pedido) (entity.Pedido, error) {
+// CriarPedido grava o pedido, seus itens e a primeira entrada do histórico em uma única
+// transação, como a versão Postgres; os itens reaproveitam um statement preparado. Qualquer
+// falha desfaz a transação, sem deixar pedido sem itens na base.
+func (repo *PedidoDbMock) CriarPedido(ctx context.Context, p entity.Pedido) (entity.Pedido, error) {
 	var idPedido int
-	tx, err := repo.Db.Begin()
+	tx, err := repo.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return p, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	criadoEm := time.Now()
+	err = tx.QueryRowContext(ctx, "INSERT INTO pedidos (cliente_cpf, status, data, metodo_pagamento, tempo_preparo_minutos) VALUES (?, ?, ?, ?, ?) RETURNING id",
+		p.Cpf, entity.StatusRecebido, criadoEm, p.MetodoPagamento, p.TempoDePreparo).Scan(&idPedido)
 	if err != nil {
-		return p, fmt.Errorf("error starting transaction: %v", err)
+		return p, fmt.Errorf("error inserting pedido: %w", err)
 	}
 
-	err = tx.QueryRow("INSERT INTO pedidos (cliente_cpf, status, data, metodo_pagamento) VALUES (?, ?, ?, ?) RETURNING id",
-		p.Cpf, "Recebido", time.Now(), p.MetodoPagamento).Scan(&idPedido)
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO produto_pedido (produto_id, pedido_id, quantidade, observacao, preco_unitario_centavos) VALUES (?, ?, ?, ?, ?) RETURNING id")
 	if err != nil {
-		tx.Rollback()
-		return p, fmt.Errorf("error inserting pedido: %v", err)
+		return p, fmt.Errorf("error preparing produto_pedido insert: %w", err)
 	}
+	defer stmt.Close()
 
-	for _, pp := range p.Produtos {
-		_, err := tx.Exec("INSERT INTO produto_pedido (produto_id, pedido_id, quantidade, observacao) VALUES (?, ?, ?, ?)",
-			pp.ProdutoId, idPedido, pp.Quantidade, pp.Observacao)
+	for i, pp := range p.Produtos {
+		err := stmt.QueryRowContext(ctx, pp.ProdutoId, idPedido, pp.Quantidade, pp.Observacao, pp.PrecoUnitario.Centavos).Scan(&p.Produtos[i].Id)
 		if err != nil {
-			tx.Rollback()
-			return p, fmt.Errorf("error inserting produto_pedido: %v", err)
+			return p, fmt.Errorf("error inserting produto_pedido: %w", err)
 		}
 	}
 
+	if _, err := inserirHistoricoSqlite(ctx, tx, idPedido, entity.EventoCriacao, entity.ResponsavelCliente, "", criadoEm); err != nil {
+		return p, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
-		return p, fmt.Errorf("error committing transaction: %v", err)
+		return p, fmt.Errorf("error committing transaction: %w", err)
 	}
 
 	p.Id = idPedido
+	p.Status = entity.StatusRecebido
+	p.CriadoEm = criadoEm
+	if err = p.CalcularTotal(); err != nil {
+		return p, err
+	}
 	return p, nil
 }
 
-func (repo *PedidoDbMock) RecuperarPedidos() ([]entity.Pedido, error) {
-	var pedidos []entity.Pedido
-	rows, err := repo.Db.Query(QUERY_PEDIDOS_SQLITE)
+func (repo *PedidoDbMock) RecuperarPedidos(ctx context.Context, filtro entity.FiltroPedidos) ([]entity.Pedido, error) {
+	query, args := consultaPedidos(filtro, func(n int) string { return fmt.Sprintf("?%d", n) })
+	rows, err := repo.Db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("error querying pedidos: %v", err)
+		return nil, fmt.Errorf("error querying pedidos: %w", err)
+	}
+	defer rows.Close()
+
+	pedidos, err := lerPedidos(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error scanning pedido: %w", err)
+	}
+
+	return pedidos, nil
+}
+
+func (repo *PedidoDbMock) RecuperarPedido(ctx context.Context, idPedido int) (entity.Pedido, error) {
+	rows, err := repo.Db.QueryContext(ctx, QUERY_PEDIDO_SQLITE, idPedido)
+	if err != nil {
+		return entity.Pedido{}, fmt.Errorf("error querying pedido: %w", err)
+	}
+	defer rows.Close()
+
+	pedidos, err := lerPedidos(rows)
+	if err != nil {
+		return entity.Pedido{}, fmt.Errorf("error scanning pedido: %w", err)
+	}
+	if len(pedidos) == 0 {
+		return entity.Pedido{}, entity.ErrPedidoNaoEncontrado
+	}
+
+	return pedidos[0], nil
+}
+
+func (repo *PedidoDbMock) RecuperarFilaCozinha(ctx context.Context) ([]entity.Pedido, error) {
+	rows, err := repo.Db.QueryContext(ctx, QUERY_FILA_COZINHA_SQLITE, entity.StatusPronto, entity.StatusEmPreparacao, entity.StatusRecebido)
+	if err != nil {
+		return nil, fmt.Errorf("error querying fila da cozinha: %w", err)
+	}
+	defer rows.Close()
+
+	pedidos, err := lerPedidos(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error scanning pedido: %w", err)
+	}
+
+	return pedidos, nil
+}
+
+func (repo *PedidoDbMock) RecuperarStatus(ctx context.Context, idPedido int) (entity.StatusPedido, error) {
+	var status entity.StatusPedido
+	err := repo.Db.QueryRowContext(ctx, "SELECT status FROM pedidos WHERE id = ?", idPedido).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", entity.ErrPedidoNaoEncontrado
+	}
+	if err != nil {
+		return "", fmt.Errorf("error querying pedido status: %w", err)
+	}
+	return status, nil
+}
+
+func (repo *PedidoDbMock) AtualizarStatus(ctx context.Context, idPedido int, statusAtual entity.StatusPedido, status entity.StatusPedido, responsavel string) (entity.HistoricoPedido, error) {
+	if !status.Valido() {
+		return entity.HistoricoPedido{}, entity.ErrStatusInvalido
+	}
+	tx, err := repo.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.HistoricoPedido{}, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE pedidos SET status = ? WHERE id = ? AND status = ?", status, idPedido, statusAtual)
+	if err != nil {
+		return entity.HistoricoPedido{}, fmt.Errorf("error updating pedido status: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return entity.HistoricoPedido{}, fmt.Errorf("error updating pedido status: %w", err)
+	}
+	if n == 0 {
+		return entity.HistoricoPedido{}, statusAlterado(tx.QueryRowContext(ctx, "SELECT status FROM pedidos WHERE id = ?", idPedido))
+	}
+	h, err := inserirHistoricoSqlite(ctx, tx, idPedido, entity.EventoStatus, responsavel, "", time.Now())
+	if err != nil {
+		return h, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return h, fmt.Errorf("error committing transaction: %w", err)
+	}
+	return h, nil
+}
+
+func (repo *PedidoDbMock) RecuperarPagamento(ctx context.Context, idPedido int) (bool, error) {
+	var pagamentoAprovado bool
+	err := repo.Db.QueryRowContext(ctx, "SELECT pagamento_aprovado FROM pedidos WHERE id = ?", idPedido).Scan(&pagamentoAprovado)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, entity.ErrPedidoNaoEncontrado
+	}
+	if err != nil {
+		return false, fmt.Errorf("error querying pedido pagamento_aprovado: %w", err)
+	}
+	return pagamentoAprovado, nil
+}
+
+func (repo *PedidoDbMock) AtualizarPagamento(ctx context.Context, idPedido int, pagamentoAprovado bool, responsavel string) (entity.HistoricoPedido, bool, error) {
+	tx, err := repo.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.HistoricoPedido{}, false, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, UPDATE_PAGAMENTO_SQLITE, pagamentoAprovado, idPedido, entity.EventoPagamento)
+	if err != nil {
+		return entity.HistoricoPedido{}, false, fmt.Errorf("error updating pedido pagamento_aprovado: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return entity.HistoricoPedido{}, false, fmt.Errorf("error updating pedido pagamento_aprovado: %w", err)
+	}
+	if n == 0 {
+		return entity.HistoricoPedido{}, false, nil
+	}
+	h, err := inserirHistoricoSqlite(ctx, tx, idPedido, entity.EventoPagamento, responsavel, "", time.Now())
+	if err != nil {
+		return h, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return h, false, fmt.Errorf("error committing transaction: %w", err)
+	}
+	return h, true, nil
+}
+
+func (repo *PedidoDbMock) RecuperarFilaPreparo(ctx context.Context) ([]entity.Pedido, error) {
+	var fila []entity.Pedido
+	rows, err := repo.Db.QueryContext(ctx, QUERY_FILA_PREPARO_SQLITE, entity.StatusRecebido, entity.StatusEmPreparacao)
+	if err != nil {
+		return nil, fmt.Errorf("error querying fila de preparo: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var r PedidoRow
-		err := rows.Scan(&r.Id, &r.Cpf, &r.Status, &r.MetodoPagamento, &r.PagamentoAprovado, &r.ProdutoId, &r.Quantidade, &r.Observacao)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning pedido: %v", err)
+		var p entity.Pedido
+		if err := rows.Scan(&p.Id, &p.Status, &p.TempoDePreparo, &p.PrevisaoPronto); err != nil {
+			return nil, fmt.Errorf("error scanning fila de preparo: %w", err)
 		}
+		fila = append(fila, p)
+	}
 
-		pedidoJaExiste := false
-		for i, p := range pedidos {
-			if p.Id == r.Id {
-				pedidoJaExiste = true
-				pedidos[i].Produtos = append(p.Produtos, entity.ProdutoPedido{
-					ProdutoId:  r.ProdutoId,
-					Quantidade: r.Quantidade,
-					Observacao: r.Observacao,
-				})
-				break
-			}
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fila de preparo: %w", err)
+	}
 
-		if !pedidoJaExiste {
-			pedidos = append(pedidos, entity.Pedido{
-				Id:                r.Id,
-				Cpf:               r.Cpf,
-				Status:            r.Status,
-				MetodoPagamento:   r.MetodoPagamento,
-				PagamentoAprovado: r.PagamentoAprovado,
-				Produtos: []entity.ProdutoPedido{
-					{
-						ProdutoId:  r.ProdutoId,
-						Quantidade: r.Quantidade,
-						Observacao: r.Observacao,
-					},
-				},
-			})
+	return fila, nil
+}
+
+func (repo *PedidoDbMock) AtualizarPrevisoes(ctx context.Context, fila []entity.Pedido) error {
+	tx, err := repo.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, "UPDATE pedidos SET previsao_pronto = ? WHERE id = ?")
+	if err != nil {
+		return fmt.Errorf("error preparing previsao_pronto update: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, p := range fila {
+		if _, err := stmt.ExecContext(ctx, p.PrevisaoPronto, p.Id); err != nil {
+			return fmt.Errorf("error updating pedido previsao_pronto: %w", err)
 		}
 	}
 
-	return pedidos, nil
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
 }
 
-func (repo *PedidoDbMock) AtualizarStatus(idPedido int, status string) error {
-	_, err := repo.Db.Exec("UPDATE pedidos SET status = ? WHERE id = ?", status, idPedido)
+func (repo *PedidoDbMock) CancelarPedido(ctx context.Context, c entity.Cancelamento, statusAtual entity.StatusPedido) error {
+	tx, err := repo.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE pedidos SET status = ?, previsao_pronto = NULL WHERE id = ? AND status = ?", entity.StatusCancelado, c.PedidoId, statusAtual)
+	if err != nil {
+		return fmt.Errorf("error cancelling pedido: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error cancelling pedido: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: status do pedido foi alterado", entity.ErrCancelamentoNaoPermitido)
+	}
+
+	valor, status := colunasReembolso(c.Reembolso)
+	_, err = tx.ExecContext(ctx, "INSERT INTO cancelamento_pedido (pedido_id, motivo, cancelado_por, cancelado_em, reembolso_centavos, reembolso_status) VALUES (?, ?, ?, ?, ?, ?)",
+		c.PedidoId, c.Motivo, c.CanceladoPor, c.CanceladoEm, valor, status)
 	if err != nil {
-		return fmt.Errorf("error updating pedido status: %v", err)
+		return fmt.Errorf("error inserting cancelamento: %w", err)
+	}
+	if _, err := inserirHistoricoSqlite(ctx, tx, c.PedidoId, entity.EventoCancelamento, c.CanceladoPor, c.Motivo, c.CanceladoEm); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
 	}
 	return nil
 }
 
-func (repo *PedidoDbMock) AtualizarPagamento(idPedido int, pagamentoAprovado bool) error {
-	_, err := repo.Db.Exec("UPDATE pedidos SET pagamento_aprovado = ? WHERE id = ?", pagamentoAprovado, idPedido)
+func (repo *PedidoDbMock) AtualizarReembolso(ctx context.Context, idPedido int, status entity.StatusReembolso) error {
+	_, err := repo.Db.ExecContext(ctx, "UPDATE cancelamento_pedido SET reembolso_status = ? WHERE pedido_id = ?", status, idPedido)
 	if err != nil {
-		return fmt.Errorf("error updating pedido pagamento_aprovado: %v", err)
+		return fmt.Errorf("error updating reembolso: %w", err)
 	}
 	return nil
 }
+
+func (repo *PedidoDbMock) AprovarPagamentoCancelado(ctx context.Context, idPedido int, valor entity.Dinheiro, responsavel string) (entity.HistoricoPedido, entity.SolicitacaoReembolso, error) {
+	s := entity.SolicitacaoReembolso{PedidoId: idPedido, Valor: valor}
+	tx, err := repo.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.HistoricoPedido{}, s, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "UPDATE pedidos SET pagamento_aprovado = TRUE WHERE id = ? AND status = ? AND NOT pagamento_aprovado", idPedido, entity.StatusCancelado)
+	if err != nil {
+		return entity.HistoricoPedido{}, s, fmt.Errorf("error updating pedido pagamento_aprovado: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return entity.HistoricoPedido{}, s, fmt.Errorf("error updating pedido pagamento_aprovado: %w", err)
+	}
+	if n == 0 {
+		return entity.HistoricoPedido{}, s, fmt.Errorf("%w: pedido não está cancelado aguardando pagamento", entity.ErrPagamentoJaProcessado)
+	}
+
+	err = tx.QueryRowContext(ctx, "UPDATE cancelamento_pedido SET reembolso_centavos = ?, reembolso_status = ? WHERE pedido_id = ? RETURNING motivo", valor.Centavos, entity.ReembolsoPendente, idPedido).Scan(&s.Motivo)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.HistoricoPedido{}, s, fmt.Errorf("%w: cancelamento do pedido não registrado", entity.ErrPedidoNaoEncontrado)
+	}
+	if err != nil {
+		return entity.HistoricoPedido{}, s, fmt.Errorf("error updating reembolso: %w", err)
+	}
+	h, err := inserirHistoricoSqlite(ctx, tx, idPedido, entity.EventoPagamento, responsavel, "", time.Now())
+	if err != nil {
+		return h, s, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return h, s, fmt.Errorf("error committing transaction: %w", err)
+	}
+	return h, s, nil
+}
+
+func (repo *PedidoDbMock) RecuperarReembolsosPendentes(ctx context.Context) ([]entity.SolicitacaoReembolso, error) {
+	rows, err := repo.Db.QueryContext(ctx, QUERY_REEMBOLSOS_PENDENTES_SQLITE, entity.ReembolsoPendente)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pending reembolsos: %w", err)
+	}
+	defer rows.Close()
+
+	solicitacoes, err := lerSolicitacoesReembolso(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error scanning pending reembolsos: %w", err)
+	}
+	return solicitacoes, nil
+}
+
+func (repo *PedidoDbMock) RecuperarHistorico(ctx context.Context, idPedido int) ([]entity.HistoricoPedido, error) {
+	rows, err := repo.Db.QueryContext(ctx, QUERY_HISTORICO_SQLITE, idPedido)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pedido_status_historico: %w", err)
+	}
+	defer rows.Close()
+
+	historico, err := lerHistorico(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error scanning pedido_status_historico: %w", err)
+	}
+	if len(historico) == 0 {
+		return nil, entity.ErrPedidoNaoEncontrado
+	}
+
+	return historico, nil
+}
+
+func inserirHistoricoSqlite(ctx context.Context, tx *sql.Tx, idPedido int, evento entity.EventoPedido, responsavel string, motivo string, registradoEm time.Time) (entity.HistoricoPedido, error) {
+	h := entity.HistoricoPedido{Evento: evento, Responsavel: responsavel, Motivo: motivo, RegistradoEm: registradoEm}
+	err := tx.QueryRowContext(ctx, INSERT_HISTORICO_SQLITE, idPedido, evento, responsavel, motivo, registradoEm).Scan(&h.Status, &h.PagamentoAprovado)
+	if errors.Is(err, sql.ErrNoRows) {
+		return h, entity.ErrPedidoNaoEncontrado
+	}
+	if err != nil {
+		return h, fmt.Errorf("error inserting pedido_status_historico: %w", err)
+	}
+	return h, nil
+}
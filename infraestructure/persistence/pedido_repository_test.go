@@ -1,7 +1,10 @@
 package persistence
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"reflect"
 	"testing"
 	"time"
 
@@ -22,15 +25,38 @@ func setupTestDB(t *testing.T) *sql.DB {
 		status TEXT NOT NULL,
 		data TIMESTAMP NOT NULL,
 		metodo_pagamento TEXT NOT NULL,
-		pagamento_aprovado BOOLEAN DEFAULT FALSE
+		pagamento_aprovado BOOLEAN DEFAULT FALSE,
+		tempo_preparo_minutos INTEGER NOT NULL DEFAULT 0,
+		previsao_pronto TIMESTAMP
 	);
 
 	CREATE TABLE produto_pedido (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		produto_id INTEGER NOT NULL,
 		pedido_id INTEGER NOT NULL,
 		quantidade INTEGER NOT NULL,
 		observacao TEXT,
-		PRIMARY KEY (produto_id, pedido_id)
+		preco_unitario_centavos INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE cancelamento_pedido (
+		pedido_id INTEGER PRIMARY KEY,
+		motivo TEXT NOT NULL,
+		cancelado_por TEXT NOT NULL,
+		cancelado_em TIMESTAMP NOT NULL,
+		reembolso_centavos INTEGER,
+		reembolso_status TEXT
+	);
+
+	CREATE TABLE pedido_status_historico (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		pedido_id INTEGER NOT NULL,
+		evento TEXT NOT NULL,
+		status TEXT NOT NULL,
+		pagamento_aprovado BOOLEAN NOT NULL,
+		responsavel TEXT NOT NULL DEFAULT '',
+		motivo TEXT NOT NULL DEFAULT '',
+		registrado_em TIMESTAMP NOT NULL
 	);
 	`)
 	if err != nil {
@@ -51,12 +77,12 @@ func TestCriarPedido(t *testing.T) {
 			Cpf:             123456789,
 			MetodoPagamento: "Cartão",
 			Produtos: []entity.ProdutoPedido{
-				{ProdutoId: 1, Quantidade: 2, Observacao: "Extra cheese"},
-				{ProdutoId: 2, Quantidade: 1, Observacao: "No onions"},
+				{ProdutoId: 1, Quantidade: 2, Observacao: "Extra cheese", PrecoUnitario: entity.Centavos(1000)},
+				{ProdutoId: 2, Quantidade: 1, Observacao: "No onions", PrecoUnitario: entity.Centavos(550)},
 			},
 		}
 
-		createdPedido, err := repo.CriarPedido(pedido)
+		createdPedido, err := repo.CriarPedido(context.Background(), pedido)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -64,6 +90,76 @@ func TestCriarPedido(t *testing.T) {
 		if createdPedido.Id == 0 {
 			t.Errorf("expected pedido ID to be generated, got 0")
 		}
+
+		if createdPedido.Total.Centavos != 2550 {
+			t.Errorf("expected total 25.5, got %v", createdPedido.Total)
+		}
+
+		if createdPedido.Produtos[0].Id == 0 || createdPedido.Produtos[1].Id == 0 {
+			t.Errorf("expected item IDs to be generated, got %+v", createdPedido.Produtos)
+		}
+	})
+
+	t.Run("Same produto in two lines with different notes", func(t *testing.T) {
+		pedido := entity.Pedido{
+			Cpf:             123456789,
+			MetodoPagamento: "Cartão",
+			Produtos: []entity.ProdutoPedido{
+				{ProdutoId: 1, Quantidade: 1, Observacao: "sem cebola", PrecoUnitario: entity.Centavos(1000)},
+				{ProdutoId: 1, Quantidade: 1, PrecoUnitario: entity.Centavos(1000)},
+			},
+		}
+
+		createdPedido, err := repo.CriarPedido(context.Background(), pedido)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		recuperado, err := repo.RecuperarPedido(context.Background(), createdPedido.Id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(recuperado.Produtos) != 2 {
+			t.Fatalf("expected 2 lines, got %+v", recuperado.Produtos)
+		}
+		if recuperado.Produtos[0].Id != createdPedido.Produtos[0].Id || recuperado.Produtos[0].Observacao != "sem cebola" || recuperado.Produtos[1].Observacao != "" {
+			t.Errorf("expected lines in insertion order, got %+v", recuperado.Produtos)
+		}
+		if recuperado.Total.Centavos != 2000 {
+			t.Errorf("expected total 20.00, got %v", recuperado.Total)
+		}
+	})
+
+	t.Run("Failed item rolls back the whole pedido", func(t *testing.T) {
+		contar := func() (n int) {
+			t.Helper()
+			err := db.QueryRow("SELECT (SELECT COUNT(*) FROM pedidos) + (SELECT COUNT(*) FROM produto_pedido) + (SELECT COUNT(*) FROM pedido_status_historico)").Scan(&n)
+			if err != nil {
+				t.Fatalf("failed to count rows: %v", err)
+			}
+			return n
+		}
+		_, err := db.Exec("CREATE TRIGGER falha_item BEFORE INSERT ON produto_pedido WHEN NEW.produto_id = 99 BEGIN SELECT RAISE(ABORT, 'falha no item'); END")
+		if err != nil {
+			t.Fatalf("failed to create trigger: %v", err)
+		}
+		defer db.Exec("DROP TRIGGER falha_item")
+
+		antes := contar()
+		pedido := entity.Pedido{
+			Cpf:             123456789,
+			MetodoPagamento: "Cartão",
+			Produtos: []entity.ProdutoPedido{
+				{ProdutoId: 1, Quantidade: 1, PrecoUnitario: entity.Centavos(1000)},
+				{ProdutoId: 99, Quantidade: 1, PrecoUnitario: entity.Centavos(1000)},
+			},
+		}
+		if _, err := repo.CriarPedido(context.Background(), pedido); err == nil {
+			t.Fatalf("expected an error inserting the second item")
+		}
+		if depois := contar(); depois != antes {
+			t.Errorf("expected no rows left behind, had %d rows and now have %d", antes, depois)
+		}
 	})
 }
 
@@ -79,14 +175,14 @@ func TestRecuperarPedidos(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to insert sample pedido: %v", err)
 	}
-	_, err = db.Exec(`INSERT INTO produto_pedido (produto_id, pedido_id, quantidade, observacao) VALUES (?, ?, ?, ?)`,
-		1, 1, 2, "Extra cheese")
+	_, err = db.Exec(`INSERT INTO produto_pedido (produto_id, pedido_id, quantidade, observacao, preco_unitario_centavos) VALUES (?, ?, ?, ?, ?)`,
+		1, 1, 2, "Extra cheese", 1250)
 	if err != nil {
 		t.Fatalf("failed to insert sample produto_pedido: %v", err)
 	}
 
 	t.Run("Retrieve pedidos", func(t *testing.T) {
-		pedidos, err := repo.RecuperarPedidos()
+		pedidos, err := repo.RecuperarPedidos(context.Background(), entity.FiltroPedidos{})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -98,6 +194,238 @@ func TestRecuperarPedidos(t *testing.T) {
 		if pedidos[0].Produtos[0].ProdutoId != 1 {
 			t.Errorf("expected produto ID 1, got %d", pedidos[0].Produtos[0].ProdutoId)
 		}
+
+		if pedidos[0].Produtos[0].Subtotal.Centavos != 2500 || pedidos[0].Total.Centavos != 2500 {
+			t.Errorf("expected subtotal and total 25, got %v and %v", pedidos[0].Produtos[0].Subtotal, pedidos[0].Total)
+		}
+	})
+
+	t.Run("Pedidos without items are listed", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO pedidos (cliente_cpf, status, data, metodo_pagamento) VALUES (?, ?, ?, ?)`,
+			123456789, "Recebido", time.Now(), "Cartão")
+		if err != nil {
+			t.Fatalf("failed to insert sample pedido: %v", err)
+		}
+
+		pedidos, err := repo.RecuperarPedidos(context.Background(), entity.FiltroPedidos{Limite: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(pedidos) != 2 || pedidos[1].Id != 2 || len(pedidos[1].Produtos) != 0 {
+			t.Errorf("expected pedido 2 to be listed without produtos, got %+v", pedidos)
+		}
+	})
+
+	t.Run("Cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := repo.RecuperarPedidos(ctx, entity.FiltroPedidos{})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestRecuperarPedidosFiltro(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := &PedidoDbMock{Db: db}
+
+	// Insert sample data
+	inicio := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	amostras := []struct {
+		cpf    int64
+		status string
+		pago   bool
+	}{
+		{111, "Recebido", false},
+		{222, "Pronto", true},
+		{111, "Pronto", true},
+		{111, "Cancelado", false},
+		{222, "Pronto", true},
+	}
+	for i, a := range amostras {
+		_, err := db.Exec(`INSERT INTO pedidos (cliente_cpf, status, data, metodo_pagamento, pagamento_aprovado) VALUES (?, ?, ?, ?, ?)`,
+			a.cpf, a.status, inicio.Add(time.Duration(i)*time.Hour), "Cartão", a.pago)
+		if err != nil {
+			t.Fatalf("failed to insert sample pedido: %v", err)
+		}
+		for produto := 1; produto <= 2; produto++ {
+			_, err = db.Exec(`INSERT INTO produto_pedido (produto_id, pedido_id, quantidade, observacao) VALUES (?, ?, ?, ?)`,
+				produto, i+1, 1, "")
+			if err != nil {
+				t.Fatalf("failed to insert sample produto_pedido: %v", err)
+			}
+		}
+	}
+
+	ids := func(pedidos []entity.Pedido) []int {
+		var r []int
+		for _, p := range pedidos {
+			r = append(r, p.Id)
+		}
+		return r
+	}
+	aprovado := true
+	de, ate := inicio.Add(time.Hour), inicio.Add(3*time.Hour)
+
+	tests := []struct {
+		name     string
+		filtro   entity.FiltroPedidos
+		expected []int
+	}{
+		{"status", entity.FiltroPedidos{Status: entity.StatusPronto}, []int{2, 3, 5}},
+		{"cpf and payment", entity.FiltroPedidos{Cpf: 111, PagamentoAprovado: &aprovado}, []int{3}},
+		{"created-at range", entity.FiltroPedidos{CriadoDe: &de, CriadoAte: &ate}, []int{2, 3, 4}},
+		{"newest first with limit", entity.FiltroPedidos{Ordenacao: entity.OrdenarPorDataDesc, Limite: 2}, []int{5, 4}},
+		{"id descending", entity.FiltroPedidos{Ordenacao: entity.OrdenarPorIdDesc, Status: entity.StatusPronto}, []int{5, 3, 2}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pedidos, err := repo.RecuperarPedidos(context.Background(), test.filtro)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := ids(pedidos); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected pedidos %v, got %v", test.expected, got)
+			}
+			for _, p := range pedidos {
+				if len(p.Produtos) != 2 {
+					t.Errorf("expected 2 produtos in pedido %d, got %d", p.Id, len(p.Produtos))
+				}
+			}
+		})
+	}
+
+	t.Run("cursor continues after the last pedido", func(t *testing.T) {
+		for _, ordenacao := range []entity.OrdenacaoPedidos{entity.OrdenarPorData, entity.OrdenarPorDataDesc, entity.OrdenarPorId, entity.OrdenarPorIdDesc} {
+			filtro := entity.FiltroPedidos{Ordenacao: ordenacao, Limite: 2}
+			var todos []int
+			for pagina := 0; pagina < 5; pagina++ {
+				pedidos, err := repo.RecuperarPedidos(context.Background(), filtro)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(pedidos) == 0 {
+					break
+				}
+				todos = append(todos, ids(pedidos)...)
+				cursor := entity.NovoCursorPedidos(ordenacao, pedidos[len(pedidos)-1])
+				filtro.Cursor = &cursor
+			}
+
+			expected := []int{1, 2, 3, 4, 5}
+			if ordenacao.Decrescente() {
+				expected = []int{5, 4, 3, 2, 1}
+			}
+			if !reflect.DeepEqual(todos, expected) {
+				t.Errorf("%s: expected pedidos %v across pages, got %v", ordenacao, expected, todos)
+			}
+		}
+	})
+}
+
+func TestRecuperarPedido(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := &PedidoDbMock{Db: db}
+
+	// Insert sample data
+	criado := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	_, err := db.Exec(`INSERT INTO pedidos (cliente_cpf, status, data, metodo_pagamento, pagamento_aprovado) VALUES (?, ?, ?, ?, ?)`,
+		123456789, "Em preparação", criado, "Cartão", true)
+	if err != nil {
+		t.Fatalf("failed to insert sample pedido: %v", err)
+	}
+	for produto, preco := range map[int]int64{1: 1000, 2: 450} {
+		_, err = db.Exec(`INSERT INTO produto_pedido (produto_id, pedido_id, quantidade, observacao, preco_unitario_centavos) VALUES (?, ?, ?, ?, ?)`,
+			produto, 1, 2, "", preco)
+		if err != nil {
+			t.Fatalf("failed to insert sample produto_pedido: %v", err)
+		}
+	}
+
+	t.Run("Retrieve pedido with items", func(t *testing.T) {
+		pedido, err := repo.RecuperarPedido(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if pedido.Id != 1 || pedido.Status != entity.StatusEmPreparacao || !pedido.PagamentoAprovado {
+			t.Errorf("unexpected pedido: %+v", pedido)
+		}
+		if len(pedido.Produtos) != 2 || pedido.Total.Centavos != 2900 {
+			t.Errorf("expected 2 produtos and total 29, got %d and %v", len(pedido.Produtos), pedido.Total)
+		}
+		if !pedido.CriadoEm.Equal(criado) {
+			t.Errorf("expected criado_em %v, got %v", criado, pedido.CriadoEm)
+		}
+	})
+
+	t.Run("Retrieve pedido without items", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO pedidos (cliente_cpf, status, data, metodo_pagamento) VALUES (?, ?, ?, ?)`,
+			123456789, "Recebido", criado, "Cartão")
+		if err != nil {
+			t.Fatalf("failed to insert sample pedido: %v", err)
+		}
+
+		pedido, err := repo.RecuperarPedido(context.Background(), 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pedido.Id != 2 || pedido.Produtos == nil || len(pedido.Produtos) != 0 || pedido.Total.Centavos != 0 {
+			t.Errorf("expected pedido 2 with an empty produto list, got %+v", pedido)
+		}
+	})
+
+	t.Run("Unknown pedido", func(t *testing.T) {
+		_, err := repo.RecuperarPedido(context.Background(), 99)
+		if !errors.Is(err, entity.ErrPedidoNaoEncontrado) {
+			t.Fatalf("expected ErrPedidoNaoEncontrado, got %v", err)
+		}
+	})
+}
+
+func TestRecuperarFilaCozinha(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := &PedidoDbMock{Db: db}
+
+	// Insert sample data
+	agora := time.Now()
+	for i, status := range []string{"Recebido", "Pronto", "Finalizado", "Em preparação", "Pronto", "Cancelado", "Recebido"} {
+		_, err := db.Exec(`INSERT INTO pedidos (cliente_cpf, status, data, metodo_pagamento) VALUES (?, ?, ?, ?)`,
+			123456789, status, agora.Add(time.Duration(i)*time.Minute), "Cartão")
+		if err != nil {
+			t.Fatalf("failed to insert sample pedido: %v", err)
+		}
+		_, err = db.Exec(`INSERT INTO produto_pedido (produto_id, pedido_id, quantidade, observacao) VALUES (?, ?, ?, ?)`,
+			1, i+1, 1, "")
+		if err != nil {
+			t.Fatalf("failed to insert sample produto_pedido: %v", err)
+		}
+	}
+
+	t.Run("Retrieve active pedidos by status priority and age", func(t *testing.T) {
+		pedidos, err := repo.RecuperarFilaCozinha(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		esperado := []int{2, 5, 4, 1, 7}
+		if len(pedidos) != len(esperado) {
+			t.Fatalf("expected %d pedidos, got %d", len(esperado), len(pedidos))
+		}
+		for i, id := range esperado {
+			if pedidos[i].Id != id {
+				t.Errorf("position %d: expected pedido %d, got %d", i, id, pedidos[i].Id)
+			}
+		}
 	})
 }
 
@@ -115,10 +443,13 @@ func TestAtualizarStatus(t *testing.T) {
 	}
 
 	t.Run("Update pedido status", func(t *testing.T) {
-		err := repo.AtualizarStatus(1, "Em Andamento")
+		h, err := repo.AtualizarStatus(context.Background(), 1, entity.StatusRecebido, entity.StatusEmPreparacao, "cozinha")
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
+		if h.Evento != entity.EventoStatus || h.Status != entity.StatusEmPreparacao || h.Responsavel != "cozinha" || h.RegistradoEm.IsZero() {
+			t.Errorf("unexpected historico entry %+v", h)
+		}
 
 		var status string
 		err = db.QueryRow(`SELECT status FROM pedidos WHERE id = ?`, 1).Scan(&status)
@@ -126,13 +457,42 @@ func TestAtualizarStatus(t *testing.T) {
 			t.Fatalf("unexpected error: %v", err)
 		}
 
-		if status != "Em Andamento" {
-			t.Errorf("expected status 'Em Andamento', got '%s'", status)
+		if status != string(entity.StatusEmPreparacao) {
+			t.Errorf("expected status 'Em preparação', got '%s'", status)
+		}
+	})
+
+	t.Run("Reject unknown status", func(t *testing.T) {
+		_, err := repo.AtualizarStatus(context.Background(), 1, entity.StatusEmPreparacao, "pronto ", "cozinha")
+		if !errors.Is(err, entity.ErrStatusInvalido) {
+			t.Fatalf("expected ErrStatusInvalido, got %v", err)
+		}
+	})
+
+	t.Run("Reject when the status changed", func(t *testing.T) {
+		_, err := repo.AtualizarStatus(context.Background(), 1, entity.StatusRecebido, entity.StatusCancelado, "cliente")
+		if !errors.Is(err, entity.ErrTransicaoStatusInvalida) {
+			t.Fatalf("expected ErrTransicaoStatusInvalida, got %v", err)
+		}
+
+		var status string
+		var entradas int
+		db.QueryRow(`SELECT status FROM pedidos WHERE id = 1`).Scan(&status)
+		db.QueryRow(`SELECT COUNT(*) FROM pedido_status_historico WHERE pedido_id = 1`).Scan(&entradas)
+		if status != string(entity.StatusEmPreparacao) || entradas != 1 {
+			t.Errorf("expected the pedido to stay 'Em preparação' with 1 historico entry, got %q and %d", status, entradas)
+		}
+	})
+
+	t.Run("Unknown pedido", func(t *testing.T) {
+		_, err := repo.AtualizarStatus(context.Background(), 99, entity.StatusEmPreparacao, entity.StatusPronto, "cozinha")
+		if !errors.Is(err, entity.ErrPedidoNaoEncontrado) {
+			t.Fatalf("expected ErrPedidoNaoEncontrado, got %v", err)
 		}
 	})
 }
 
-func TestAtualizarPagamento(t *testing.T) {
+func TestRecuperarStatus(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 
@@ -145,12 +505,47 @@ func TestAtualizarPagamento(t *testing.T) {
 		t.Fatalf("failed to insert sample pedido: %v", err)
 	}
 
-	t.Run("Update pagamento_aprovado", func(t *testing.T) {
-		err := repo.AtualizarPagamento(1, true)
+	t.Run("Retrieve pedido status", func(t *testing.T) {
+		status, err := repo.RecuperarStatus(context.Background(), 1)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 
+		if status != entity.StatusRecebido {
+			t.Errorf("expected status 'Recebido', got '%s'", status)
+		}
+	})
+
+	t.Run("Unknown pedido", func(t *testing.T) {
+		_, err := repo.RecuperarStatus(context.Background(), 99)
+		if !errors.Is(err, entity.ErrPedidoNaoEncontrado) {
+			t.Fatalf("expected ErrPedidoNaoEncontrado, got %v", err)
+		}
+	})
+}
+
+func TestAtualizarPagamento(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := &PedidoDbMock{Db: db}
+
+	// Insert sample data
+	_, err := db.Exec(`INSERT INTO pedidos (cliente_cpf, status, data, metodo_pagamento) VALUES (?, ?, ?, ?)`,
+		123456789, "Recebido", time.Now(), "Cartão")
+	if err != nil {
+		t.Fatalf("failed to insert sample pedido: %v", err)
+	}
+
+	t.Run("Update pagamento_aprovado", func(t *testing.T) {
+		h, alterado, err := repo.AtualizarPagamento(context.Background(), 1, true, entity.ResponsavelPagamento)
+		if err != nil || !alterado {
+			t.Fatalf("expected the payment to be recorded, got %v, %v", alterado, err)
+		}
+		if h.Evento != entity.EventoPagamento || h.Status != entity.StatusRecebido || !h.PagamentoAprovado {
+			t.Errorf("unexpected historico entry %+v", h)
+		}
+
 		var pagamentoAprovado bool
 		err = db.QueryRow(`SELECT pagamento_aprovado FROM pedidos WHERE id = ?`, 1).Scan(&pagamentoAprovado)
 		if err != nil {
@@ -161,4 +556,313 @@ func TestAtualizarPagamento(t *testing.T) {
 			t.Errorf("expected pagamento_aprovado to be true, got false")
 		}
 	})
+
+	t.Run("Re-delivered notification is a no-op", func(t *testing.T) {
+		_, alterado, err := repo.AtualizarPagamento(context.Background(), 1, true, entity.ResponsavelPagamento)
+		if err != nil || alterado {
+			t.Fatalf("expected a no-op, got %v, %v", alterado, err)
+		}
+
+		var entradas int
+		db.QueryRow(`SELECT COUNT(*) FROM pedido_status_historico WHERE pedido_id = 1 AND evento = ?`, entity.EventoPagamento).Scan(&entradas)
+		if entradas != 1 {
+			t.Errorf("expected a single payment entry, got %d", entradas)
+		}
+	})
+
+	t.Run("Record a decline once", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO pedidos (id, cliente_cpf, status, data, metodo_pagamento) VALUES (2, ?, ?, ?, ?)`, 123456789, "Recebido", time.Now(), "Cartão")
+		if err != nil {
+			t.Fatalf("failed to insert sample pedido: %v", err)
+		}
+
+		h, alterado, err := repo.AtualizarPagamento(context.Background(), 2, false, entity.ResponsavelPagamento)
+		if err != nil || !alterado || h.PagamentoAprovado {
+			t.Fatalf("expected the decline to be recorded, got %+v, %v, %v", h, alterado, err)
+		}
+		if _, alterado, _ := repo.AtualizarPagamento(context.Background(), 2, false, entity.ResponsavelPagamento); alterado {
+			t.Errorf("expected a re-delivered decline to be a no-op")
+		}
+		if _, alterado, _ := repo.AtualizarPagamento(context.Background(), 2, true, entity.ResponsavelPagamento); !alterado {
+			t.Errorf("expected an approval after the decline to be recorded")
+		}
+	})
+
+	t.Run("Retrieve pagamento_aprovado", func(t *testing.T) {
+		pagamentoAprovado, err := repo.RecuperarPagamento(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !pagamentoAprovado {
+			t.Errorf("expected pagamento_aprovado to be true, got false")
+		}
+
+		_, err = repo.RecuperarPagamento(context.Background(), 99)
+		if !errors.Is(err, entity.ErrPedidoNaoEncontrado) {
+			t.Fatalf("expected ErrPedidoNaoEncontrado, got %v", err)
+		}
+	})
+}
+
+func TestRecuperarFilaPreparo(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := &PedidoDbMock{Db: db}
+
+	// Insert sample data
+	agora := time.Now()
+	for i, status := range []string{"Em preparação", "Finalizado", "Recebido"} {
+		_, err := db.Exec(`INSERT INTO pedidos (cliente_cpf, status, data, metodo_pagamento, tempo_preparo_minutos) VALUES (?, ?, ?, ?, ?)`,
+			123456789, status, agora.Add(time.Duration(i)*time.Minute), "Cartão", 10)
+		if err != nil {
+			t.Fatalf("failed to insert sample pedido: %v", err)
+		}
+	}
+
+	t.Run("Retrieve active pedidos in arrival order", func(t *testing.T) {
+		fila, err := repo.RecuperarFilaPreparo(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(fila) != 2 || fila[0].Id != 1 || fila[1].Id != 3 {
+			t.Fatalf("expected pedidos 1 and 3, got %+v", fila)
+		}
+
+		if fila[0].TempoDePreparo != 10 || fila[0].PrevisaoPronto != nil {
+			t.Errorf("unexpected queue entry: %+v", fila[0])
+		}
+	})
+
+	t.Run("Update previsao_pronto of the whole queue", func(t *testing.T) {
+		previsao := agora.Add(10 * time.Minute).Truncate(time.Second)
+		seguinte := previsao.Add(10 * time.Minute)
+		if err := repo.AtualizarPrevisoes(context.Background(), []entity.Pedido{{Id: 1, PrevisaoPronto: &previsao}, {Id: 3, PrevisaoPronto: &seguinte}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		fila, err := repo.RecuperarFilaPreparo(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if fila[0].PrevisaoPronto == nil || !fila[0].PrevisaoPronto.Equal(previsao) {
+			t.Errorf("expected previsao %v, got %v", previsao, fila[0].PrevisaoPronto)
+		}
+		if fila[1].PrevisaoPronto == nil || !fila[1].PrevisaoPronto.Equal(seguinte) {
+			t.Errorf("expected previsao %v, got %v", seguinte, fila[1].PrevisaoPronto)
+		}
+	})
+}
+
+func TestCancelarPedido(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := &PedidoDbMock{Db: db}
+	ctx := context.Background()
+
+	previsao := time.Now().Add(15 * time.Minute)
+	for _, status := range []entity.StatusPedido{entity.StatusEmPreparacao, entity.StatusRecebido} {
+		_, err := db.Exec(`INSERT INTO pedidos (cliente_cpf, status, data, metodo_pagamento, previsao_pronto) VALUES (?, ?, ?, ?, ?)`,
+			123456789, status, time.Now(), "Cartão", previsao)
+		if err != nil {
+			t.Fatalf("failed to insert sample pedido: %v", err)
+		}
+	}
+
+	t.Run("Cancel and record the refund", func(t *testing.T) {
+		c := entity.Cancelamento{
+			PedidoId:     1,
+			Motivo:       "item em falta",
+			CanceladoPor: "cozinha",
+			CanceladoEm:  time.Now(),
+			Reembolso:    &entity.Reembolso{Valor: entity.Centavos(2550), Status: entity.ReembolsoPendente},
+		}
+		if err := repo.CancelarPedido(ctx, c, entity.StatusEmPreparacao); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var status string
+		var previsaoPronto sql.NullTime
+		if err := db.QueryRow(`SELECT status, previsao_pronto FROM pedidos WHERE id = 1`).Scan(&status, &previsaoPronto); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if status != string(entity.StatusCancelado) || previsaoPronto.Valid {
+			t.Errorf("expected a cancelled pedido without previsao, got %q %v", status, previsaoPronto)
+		}
+
+		if err := repo.AtualizarReembolso(ctx, 1, entity.ReembolsoSolicitado); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var motivo, canceladoPor, reembolsoStatus string
+		var centavos int64
+		err := db.QueryRow(`SELECT motivo, cancelado_por, reembolso_centavos, reembolso_status FROM cancelamento_pedido WHERE pedido_id = 1`).Scan(&motivo, &canceladoPor, &centavos, &reembolsoStatus)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if motivo != "item em falta" || canceladoPor != "cozinha" || centavos != 2550 || reembolsoStatus != string(entity.ReembolsoSolicitado) {
+			t.Errorf("unexpected cancelamento row: %q %q %d %q", motivo, canceladoPor, centavos, reembolsoStatus)
+		}
+	})
+
+	t.Run("Cancel without refund", func(t *testing.T) {
+		c := entity.Cancelamento{PedidoId: 2, Motivo: "cliente desistiu", CanceladoPor: "cliente", CanceladoEm: time.Now()}
+		if err := repo.CancelarPedido(ctx, c, entity.StatusRecebido); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var centavos sql.NullInt64
+		var reembolsoStatus sql.NullString
+		if err := db.QueryRow(`SELECT reembolso_centavos, reembolso_status FROM cancelamento_pedido WHERE pedido_id = 2`).Scan(&centavos, &reembolsoStatus); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if centavos.Valid || reembolsoStatus.Valid {
+			t.Errorf("expected no refund columns, got %v %v", centavos, reembolsoStatus)
+		}
+	})
+
+	t.Run("Reject when the status changed", func(t *testing.T) {
+		c := entity.Cancelamento{PedidoId: 1, Motivo: "de novo", CanceladoPor: "cliente", CanceladoEm: time.Now()}
+		err := repo.CancelarPedido(ctx, c, entity.StatusEmPreparacao)
+		if !errors.Is(err, entity.ErrCancelamentoNaoPermitido) {
+			t.Errorf("expected ErrCancelamentoNaoPermitido, got %v", err)
+		}
+
+		var cancelamentos int
+		db.QueryRow(`SELECT COUNT(*) FROM cancelamento_pedido`).Scan(&cancelamentos)
+		if cancelamentos != 2 {
+			t.Errorf("expected the rejected cancelamento not to be stored, got %d rows", cancelamentos)
+		}
+	})
+
+	t.Run("Approve the payment of a cancelled pedido", func(t *testing.T) {
+		h, solicitacao, err := repo.AprovarPagamentoCancelado(ctx, 2, entity.Centavos(1800), entity.ResponsavelPagamento)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := entity.SolicitacaoReembolso{PedidoId: 2, Valor: entity.Centavos(1800), Motivo: "cliente desistiu"}
+		if solicitacao != expected {
+			t.Errorf("expected %+v, got %+v", expected, solicitacao)
+		}
+		if h.Evento != entity.EventoPagamento || h.Status != entity.StatusCancelado || !h.PagamentoAprovado || h.Responsavel != entity.ResponsavelPagamento {
+			t.Errorf("unexpected historico: %+v", h)
+		}
+
+		var centavos int64
+		var reembolsoStatus string
+		if err := db.QueryRow(`SELECT reembolso_centavos, reembolso_status FROM cancelamento_pedido WHERE pedido_id = 2`).Scan(&centavos, &reembolsoStatus); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if centavos != 1800 || reembolsoStatus != string(entity.ReembolsoPendente) {
+			t.Errorf("expected a pending refund of 18.00, got %d %q", centavos, reembolsoStatus)
+		}
+
+		if _, _, err := repo.AprovarPagamentoCancelado(ctx, 2, entity.Centavos(1800), entity.ResponsavelPagamento); !errors.Is(err, entity.ErrPagamentoJaProcessado) {
+			t.Errorf("expected ErrPagamentoJaProcessado for a repeated approval, got %v", err)
+		}
+	})
+
+	t.Run("List pending refunds", func(t *testing.T) {
+		pendentes, err := repo.RecuperarReembolsosPendentes(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := []entity.SolicitacaoReembolso{{PedidoId: 2, Valor: entity.Centavos(1800), Motivo: "cliente desistiu"}}
+		if !reflect.DeepEqual(pendentes, expected) {
+			t.Errorf("expected only the pending refund %+v, got %+v", expected, pendentes)
+		}
+	})
+
+	t.Run("Approve the payment of a pedido without cancelamento", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO pedidos (id, cliente_cpf, status, data, metodo_pagamento) VALUES (3, ?, ?, ?, ?)`, 123456789, entity.StatusCancelado, time.Now(), "Cartão")
+		if err != nil {
+			t.Fatalf("failed to insert sample pedido: %v", err)
+		}
+
+		if _, _, err := repo.AprovarPagamentoCancelado(ctx, 3, entity.Centavos(1800), entity.ResponsavelPagamento); !errors.Is(err, entity.ErrPedidoNaoEncontrado) {
+			t.Errorf("expected ErrPedidoNaoEncontrado, got %v", err)
+		}
+		if pago, _ := repo.RecuperarPagamento(ctx, 3); pago {
+			t.Errorf("expected the approval to be rolled back")
+		}
+	})
+}
+
+func TestRecuperarHistorico(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := &PedidoDbMock{Db: db}
+	ctx := context.Background()
+
+	pedido, err := repo.CriarPedido(ctx, entity.Pedido{
+		Cpf:             123456789,
+		MetodoPagamento: "Cartão",
+		Produtos:        []entity.ProdutoPedido{{ProdutoId: 1, Quantidade: 1, PrecoUnitario: entity.Centavos(1000)}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create pedido: %v", err)
+	}
+
+	t.Run("Every change is recorded in order", func(t *testing.T) {
+		if _, _, err := repo.AtualizarPagamento(ctx, pedido.Id, true, entity.ResponsavelPagamento); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := repo.AtualizarStatus(ctx, pedido.Id, entity.StatusRecebido, entity.StatusEmPreparacao, entity.ResponsavelPagamento); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		c := entity.Cancelamento{PedidoId: pedido.Id, Motivo: "item em falta", CanceladoPor: "cozinha", CanceladoEm: time.Now()}
+		if err := repo.CancelarPedido(ctx, c, entity.StatusEmPreparacao); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		historico, err := repo.RecuperarHistorico(ctx, pedido.Id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		esperado := []entity.HistoricoPedido{
+			{Evento: entity.EventoCriacao, Status: entity.StatusRecebido, Responsavel: entity.ResponsavelCliente},
+			{Evento: entity.EventoPagamento, Status: entity.StatusRecebido, PagamentoAprovado: true, Responsavel: entity.ResponsavelPagamento},
+			{Evento: entity.EventoStatus, Status: entity.StatusEmPreparacao, PagamentoAprovado: true, Responsavel: entity.ResponsavelPagamento},
+			{Evento: entity.EventoCancelamento, Status: entity.StatusCancelado, PagamentoAprovado: true, Responsavel: "cozinha", Motivo: "item em falta"},
+		}
+		if len(historico) != len(esperado) {
+			t.Fatalf("expected %d entries, got %+v", len(esperado), historico)
+		}
+		for i, h := range historico {
+			if h.RegistradoEm.IsZero() {
+				t.Errorf("entry %d: expected registrado_em to be set", i)
+			}
+			h.RegistradoEm = time.Time{}
+			if h != esperado[i] {
+				t.Errorf("entry %d: expected %+v, got %+v", i, esperado[i], h)
+			}
+		}
+	})
+
+	t.Run("Rejected cancellation is not recorded", func(t *testing.T) {
+		c := entity.Cancelamento{PedidoId: pedido.Id, Motivo: "de novo", CanceladoPor: "cliente", CanceladoEm: time.Now()}
+		if err := repo.CancelarPedido(ctx, c, entity.StatusEmPreparacao); !errors.Is(err, entity.ErrCancelamentoNaoPermitido) {
+			t.Fatalf("expected ErrCancelamentoNaoPermitido, got %v", err)
+		}
+
+		historico, err := repo.RecuperarHistorico(ctx, pedido.Id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(historico) != 4 {
+			t.Errorf("expected 4 entries, got %d", len(historico))
+		}
+	})
+
+	t.Run("Unknown pedido", func(t *testing.T) {
+		_, err := repo.RecuperarHistorico(ctx, 99)
+		if !errors.Is(err, entity.ErrPedidoNaoEncontrado) {
+			t.Fatalf("expected ErrPedidoNaoEncontrado, got %v", err)
+		}
+	})
 }
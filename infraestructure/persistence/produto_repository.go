@@ -2,57 +2,90 @@ package persistence
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/gomesmatheus/tc-pedido/domain/entity"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type ProdutoDbConnection struct {
-	Db *pgx.Conn
+	Db *pgxpool.Pool
 }
 
-func (repo *ProdutoDbConnection) CriarProduto(p entity.Produto) (entity.Produto, error) {
-	_, err := repo.Db.Exec(context.Background(), "INSERT INTO produtos (categoria_id, nome, descricao, preco, tempo_de_preparo_minutos) VALUES ($1, $2, $3, $4, $5)", p.CategoriaId, p.Nome, p.Descricao, p.Preco, p.TempoDePreparo)
+func (repo *ProdutoDbConnection) CriarProduto(ctx context.Context, p entity.Produto) (entity.Produto, error) {
+	_, err := repo.Db.Exec(ctx, "INSERT INTO produtos (categoria_id, nome, descricao, preco_centavos, tempo_de_preparo_minutos) VALUES ($1, $2, $3, $4, $5)", p.CategoriaId, p.Nome, p.Descricao, p.Preco.Centavos, p.TempoDePreparo)
 	if err != nil {
 		fmt.Println("Erro ao inserir produto na base de dados", err)
 	}
 	return p, err
 }
 
-func (repo *ProdutoDbConnection) RecuperarProdutos(categoriaId int) ([]entity.Produto, error) {
-	var produtos []entity.Produto
-	rows, err := repo.Db.Query(context.Background(), "SELECT id, categoria_id, nome, descricao, preco, tempo_de_preparo_minutos FROM produtos WHERE categoria_id = $1", categoriaId)
-	defer rows.Close()
+func (repo *ProdutoDbConnection) RecuperarProdutos(ctx context.Context, categoriaId int) ([]entity.Produto, error) {
+	rows, err := repo.Db.Query(ctx, "SELECT id, categoria_id, nome, descricao, preco_centavos, tempo_de_preparo_minutos FROM produtos WHERE categoria_id = $1 ORDER BY id", categoriaId)
 	if err != nil {
 		fmt.Println("Erro ao buscar por categoria_id", categoriaId)
 		fmt.Println(err)
 		return nil, err
 	}
+	defer rows.Close()
+
+	return lerProdutos(rows)
+}
+
+func (repo *ProdutoDbConnection) RecuperarTodosProdutos(ctx context.Context) ([]entity.Produto, error) {
+	rows, err := repo.Db.Query(ctx, "SELECT id, categoria_id, nome, descricao, preco_centavos, tempo_de_preparo_minutos FROM produtos ORDER BY id")
+	if err != nil {
+		fmt.Println("Erro ao buscar produtos", err)
+		return nil, err
+	}
+	defer rows.Close()
 
+	return lerProdutos(rows)
+}
+
+func lerProdutos(rows pgx.Rows) ([]entity.Produto, error) {
+	var produtos []entity.Produto
 	for rows.Next() {
 		var p entity.Produto
-		if err = rows.Scan(&p.Id, &p.CategoriaId, &p.Nome, &p.Descricao, &p.Preco, &p.TempoDePreparo); err != nil {
+		var preco int64
+		if err := rows.Scan(&p.Id, &p.CategoriaId, &p.Nome, &p.Descricao, &preco, &p.TempoDePreparo); err != nil {
 			fmt.Println("Erro fazendo scanning de produto")
 			fmt.Println(err)
 			return nil, err
 		}
+		p.Preco = entity.Centavos(preco)
 		produtos = append(produtos, p)
 	}
 
-	return produtos, err
+	return produtos, rows.Err()
+}
+
+func (repo *ProdutoDbConnection) RecuperarProduto(ctx context.Context, id int) (entity.Produto, error) {
+	var p entity.Produto
+	var preco int64
+	err := repo.Db.QueryRow(ctx, "SELECT id, categoria_id, nome, descricao, preco_centavos, tempo_de_preparo_minutos FROM produtos WHERE id = $1", id).Scan(&p.Id, &p.CategoriaId, &p.Nome, &p.Descricao, &preco, &p.TempoDePreparo)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, entity.ErrProdutoNaoEncontrado
+	}
+	if err != nil {
+		fmt.Println("Erro ao buscar produto por id", id, err)
+	}
+	p.Preco = entity.Centavos(preco)
+	return p, err
 }
 
-func (repo *ProdutoDbConnection) AtualizarProduto(id int, p entity.Produto) error {
-	_, err := repo.Db.Exec(context.Background(), "UPDATE produtos set categoria_id = $1, nome = $2, descricao = $3, preco = $4, tempo_de_preparo_minutos = $5 WHERE id = $6", p.CategoriaId, p.Nome, p.Descricao, p.Preco, p.TempoDePreparo, id)
+func (repo *ProdutoDbConnection) AtualizarProduto(ctx context.Context, id int, p entity.Produto) error {
+	_, err := repo.Db.Exec(ctx, "UPDATE produtos set categoria_id = $1, nome = $2, descricao = $3, preco_centavos = $4, tempo_de_preparo_minutos = $5 WHERE id = $6", p.CategoriaId, p.Nome, p.Descricao, p.Preco.Centavos, p.TempoDePreparo, id)
 	if err != nil {
 		fmt.Println("Erro ao atualizar produto na base de dados", err)
 	}
 	return err
 }
 
-func (repo *ProdutoDbConnection) DeletarProduto(id int) error {
-	_, err := repo.Db.Exec(context.Background(), "DELETE FROM produtos WHERE id = $1", id)
+func (repo *ProdutoDbConnection) DeletarProduto(ctx context.Context, id int) error {
+	_, err := repo.Db.Exec(ctx, "DELETE FROM produtos WHERE id = $1", id)
 	if err != nil {
 		fmt.Println("Erro ao deletar produto da base de dados", err)
 	}
@@ -1,7 +1,9 @@
 package persistence
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/gomesmatheus/tc-pedido/domain/entity"
@@ -12,73 +14,102 @@ type ProdutoDbMock struct {
 	Db *sql.DB
 }
 
-func (repo *ProdutoDbMock) CriarProduto(p entity.Produto) (entity.Produto, error) {
-	_, err := repo.Db.Exec(
-		"INSERT INTO produtos (categoria_id, nome, descricao, preco, tempo_de_preparo_minutos) VALUES (?, ?, ?, ?, ?)",
-		p.CategoriaId, p.Nome, p.Descricao, p.Preco, p.TempoDePreparo,
+func (repo *ProdutoDbMock) CriarProduto(ctx context.Context, p entity.Produto) (entity.Produto, error) {
+	_, err := repo.Db.ExecContext(ctx,
+		"INSERT INTO produtos (categoria_id, nome, descricao, preco_centavos, tempo_de_preparo_minutos) VALUES (?, ?, ?, ?, ?)",
+		p.CategoriaId, p.Nome, p.Descricao, p.Preco.Centavos, p.TempoDePreparo,
 	)
 	if err != nil {
-		return p, fmt.Errorf("erro ao inserir produto na base de dados: %v", err)
+		return p, fmt.Errorf("erro ao inserir produto na base de dados: %w", err)
 	}
 	return p, nil
 }
 
-func (repo *ProdutoDbMock) RecuperarProdutos(categoriaId int) ([]entity.Produto, error) {
-	var produtos []entity.Produto
-
-	rows, err := repo.Db.Query(
-		"SELECT id, categoria_id, nome, descricao, preco, tempo_de_preparo_minutos FROM produtos WHERE categoria_id = ?",
+func (repo *ProdutoDbMock) RecuperarProdutos(ctx context.Context, categoriaId int) ([]entity.Produto, error) {
+	rows, err := repo.Db.QueryContext(ctx,
+		"SELECT id, categoria_id, nome, descricao, preco_centavos, tempo_de_preparo_minutos FROM produtos WHERE categoria_id = ? ORDER BY id",
 		categoriaId,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar produtos por categoria_id (%d): %v", categoriaId, err)
+		return nil, fmt.Errorf("erro ao buscar produtos por categoria_id (%d): %w", categoriaId, err)
 	}
 	defer rows.Close()
 
-	fmt.Println(rows)
+	return lerProdutosLocal(rows)
+}
+
+func (repo *ProdutoDbMock) RecuperarTodosProdutos(ctx context.Context) ([]entity.Produto, error) {
+	rows, err := repo.Db.QueryContext(ctx, "SELECT id, categoria_id, nome, descricao, preco_centavos, tempo_de_preparo_minutos FROM produtos ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar produtos: %w", err)
+	}
+	defer rows.Close()
+
+	return lerProdutosLocal(rows)
+}
+
+func lerProdutosLocal(rows *sql.Rows) ([]entity.Produto, error) {
+	var produtos []entity.Produto
 	for rows.Next() {
 		var p entity.Produto
 		var id sql.NullInt64
-		var categoriaId2 sql.NullInt64
+		var categoriaId sql.NullInt64
 		var nome, descricao sql.NullString
-		var preco sql.NullFloat64
+		var preco sql.NullInt64
 		var tempoDePreparo sql.NullInt64
 
-		if err := rows.Scan(&id, &categoriaId2, &nome, &descricao, &preco, &tempoDePreparo); err != nil {
+		if err := rows.Scan(&id, &categoriaId, &nome, &descricao, &preco, &tempoDePreparo); err != nil {
 			fmt.Println(err)
-			return nil, fmt.Errorf("erro ao fazer scanning de produto: %v", err)
+			return nil, fmt.Errorf("erro ao fazer scanning de produto: %w", err)
 		}
 		p.Id = int(id.Int64)
-		p.CategoriaId = int(categoriaId2.Int64)
+		p.CategoriaId = int(categoriaId.Int64)
 		p.Nome = nome.String
 		p.Descricao = descricao.String
-		p.Preco = float32(preco.Float64)
+		p.Preco = entity.Centavos(preco.Int64)
 		p.TempoDePreparo = int(tempoDePreparo.Int64)
 		produtos = append(produtos, p)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar pelos produtos: %v", err)
+		return nil, fmt.Errorf("erro ao iterar pelos produtos: %w", err)
 	}
 
 	return produtos, nil
 }
 
-func (repo *ProdutoDbMock) AtualizarProduto(id int, p entity.Produto) error {
-	_, err := repo.Db.Exec(
-		"UPDATE produtos SET categoria_id = ?, nome = ?, descricao = ?, preco = ?, tempo_de_preparo_minutos = ? WHERE id = ?",
-		p.CategoriaId, p.Nome, p.Descricao, p.Preco, p.TempoDePreparo, id,
+func (repo *ProdutoDbMock) RecuperarProduto(ctx context.Context, id int) (entity.Produto, error) {
+	var p entity.Produto
+	var preco int64
+	err := repo.Db.QueryRowContext(ctx,
+		"SELECT id, categoria_id, nome, descricao, preco_centavos, tempo_de_preparo_minutos FROM produtos WHERE id = ?",
+		id,
+	).Scan(&p.Id, &p.CategoriaId, &p.Nome, &p.Descricao, &preco, &p.TempoDePreparo)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, entity.ErrProdutoNaoEncontrado
+	}
+	if err != nil {
+		return p, fmt.Errorf("erro ao buscar produto por id (%d): %v", id, err)
+	}
+	p.Preco = entity.Centavos(preco)
+	return p, nil
+}
+
+func (repo *ProdutoDbMock) AtualizarProduto(ctx context.Context, id int, p entity.Produto) error {
+	_, err := repo.Db.ExecContext(ctx,
+		"UPDATE produtos SET categoria_id = ?, nome = ?, descricao = ?, preco_centavos = ?, tempo_de_preparo_minutos = ? WHERE id = ?",
+		p.CategoriaId, p.Nome, p.Descricao, p.Preco.Centavos, p.TempoDePreparo, id,
 	)
 	if err != nil {
-		return fmt.Errorf("erro ao atualizar produto na base de dados: %v", err)
+		return fmt.Errorf("erro ao atualizar produto na base de dados: %w", err)
 	}
 	return nil
 }
 
-func (repo *ProdutoDbMock) DeletarProduto(id int) error {
-	_, err := repo.Db.Exec("DELETE FROM produtos WHERE id = ?", id)
+func (repo *ProdutoDbMock) DeletarProduto(ctx context.Context, id int) error {
+	_, err := repo.Db.ExecContext(ctx, "DELETE FROM produtos WHERE id = ?", id)
 	if err != nil {
-		return fmt.Errorf("erro ao deletar produto da base de dados: %v", err)
+		return fmt.Errorf("erro ao deletar produto da base de dados: %w", err)
 	}
 	return nil
 }
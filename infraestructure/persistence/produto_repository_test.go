@@ -1,7 +1,9 @@
 package persistence
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"testing"
 
 	"github.com/gomesmatheus/tc-pedido/domain/entity"
@@ -20,7 +22,7 @@ func setupProdutoTestDB(t *testing.T) *sql.DB {
 		categoria_id INTEGER NOT NULL,
 		nome TEXT NOT NULL,
 		descricao TEXT,
-		preco REAL NOT NULL,
+		preco_centavos INTEGER NOT NULL,
 		tempo_de_preparo_minutos INTEGER NOT NULL
 	);
 	`)
@@ -42,11 +44,11 @@ func TestCriarProduto(t *testing.T) {
 			CategoriaId:    1,
 			Nome:           "Pizza Margherita",
 			Descricao:      "Classic pizza with tomato, mozzarella, and basil",
-			Preco:          29.99,
+			Preco:          entity.Centavos(2999),
 			TempoDePreparo: 15,
 		}
 
-		createdProduto, err := repo.CriarProduto(produto)
+		createdProduto, err := repo.CriarProduto(context.Background(), produto)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -64,14 +66,14 @@ func TestRecuperarProdutos(t *testing.T) {
 	repo := &ProdutoDbMock{Db: db}
 
 	// Insert sample data
-	_, err := db.Exec(`INSERT INTO produtos (categoria_id, nome, descricao, preco, tempo_de_preparo_minutos) VALUES (?, ?, ?, ?, ?)`,
-		1, "Pizza Margherita", "Classic pizza with tomato, mozzarella, and basil", 29.99, 15)
+	_, err := db.Exec(`INSERT INTO produtos (categoria_id, nome, descricao, preco_centavos, tempo_de_preparo_minutos) VALUES (?, ?, ?, ?, ?)`,
+		1, "Pizza Margherita", "Classic pizza with tomato, mozzarella, and basil", 2999, 15)
 	if err != nil {
 		t.Fatalf("failed to insert sample produto: %v", err)
 	}
 
 	t.Run("Retrieve produtos by categoria_id", func(t *testing.T) {
-		produtos, err := repo.RecuperarProdutos(1)
+		produtos, err := repo.RecuperarProdutos(context.Background(), 1)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -86,6 +88,75 @@ func TestRecuperarProdutos(t *testing.T) {
 	})
 }
 
+func TestRecuperarTodosProdutos(t *testing.T) {
+	db := setupProdutoTestDB(t)
+	defer db.Close()
+
+	repo := &ProdutoDbMock{Db: db}
+
+	// Insert sample data
+	for i, nome := range []string{"Pizza Margherita", "Refrigerante", "Pudim"} {
+		_, err := db.Exec(`INSERT INTO produtos (categoria_id, nome, descricao, preco_centavos, tempo_de_preparo_minutos) VALUES (?, ?, ?, ?, ?)`,
+			i+1, nome, "", 1000, 5)
+		if err != nil {
+			t.Fatalf("failed to insert sample produto: %v", err)
+		}
+	}
+
+	t.Run("Retrieve every produto ordered by id", func(t *testing.T) {
+		produtos, err := repo.RecuperarTodosProdutos(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(produtos) != 3 {
+			t.Fatalf("expected 3 produtos, got %d", len(produtos))
+		}
+
+		for i, p := range produtos {
+			if p.Id != i+1 || p.CategoriaId != i+1 {
+				t.Errorf("unexpected produto at position %d: %+v", i, p)
+			}
+		}
+	})
+}
+
+func TestRecuperarProduto(t *testing.T) {
+	db := setupProdutoTestDB(t)
+	defer db.Close()
+
+	repo := &ProdutoDbMock{Db: db}
+
+	// Insert sample data
+	_, err := db.Exec(`INSERT INTO produtos (categoria_id, nome, descricao, preco_centavos, tempo_de_preparo_minutos) VALUES (?, ?, ?, ?, ?)`,
+		1, "Pizza Margherita", "Classic pizza with tomato, mozzarella, and basil", 2999, 15)
+	if err != nil {
+		t.Fatalf("failed to insert sample produto: %v", err)
+	}
+
+	t.Run("Retrieve produto by ID", func(t *testing.T) {
+		produto, err := repo.RecuperarProduto(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if produto.Nome != "Pizza Margherita" {
+			t.Errorf("expected produto name to be 'Pizza Margherita', got '%s'", produto.Nome)
+		}
+
+		if produto.Preco != entity.Centavos(2999) {
+			t.Errorf("expected produto price to be 29.99, got %s", produto.Preco)
+		}
+	})
+
+	t.Run("Unknown produto", func(t *testing.T) {
+		_, err := repo.RecuperarProduto(context.Background(), 99)
+		if !errors.Is(err, entity.ErrProdutoNaoEncontrado) {
+			t.Fatalf("expected ErrProdutoNaoEncontrado, got %v", err)
+		}
+	})
+}
+
 func TestAtualizarProduto(t *testing.T) {
 	db := setupProdutoTestDB(t)
 	defer db.Close()
@@ -93,8 +164,8 @@ func TestAtualizarProduto(t *testing.T) {
 	repo := &ProdutoDbMock{Db: db}
 
 	// Insert sample data
-	_, err := db.Exec(`INSERT INTO produtos (categoria_id, nome, descricao, preco, tempo_de_preparo_minutos) VALUES (?, ?, ?, ?, ?)`,
-		1, "Pizza Margherita", "Classic pizza with tomato, mozzarella, and basil", 29.99, 15)
+	_, err := db.Exec(`INSERT INTO produtos (categoria_id, nome, descricao, preco_centavos, tempo_de_preparo_minutos) VALUES (?, ?, ?, ?, ?)`,
+		1, "Pizza Margherita", "Classic pizza with tomato, mozzarella, and basil", 2999, 15)
 	if err != nil {
 		t.Fatalf("failed to insert sample produto: %v", err)
 	}
@@ -104,18 +175,18 @@ func TestAtualizarProduto(t *testing.T) {
 			CategoriaId:    1,
 			Nome:           "Pizza Pepperoni",
 			Descricao:      "Spicy pepperoni pizza with cheese",
-			Preco:          35,
+			Preco:          entity.Centavos(3500),
 			TempoDePreparo: 20,
 		}
 
-		err := repo.AtualizarProduto(1, produto)
+		err := repo.AtualizarProduto(context.Background(), 1, produto)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 
 		var nome, descricao string
-		var preco float64
-		err = db.QueryRow(`SELECT nome, descricao, preco FROM produtos WHERE id = ?`, 1).Scan(&nome, &descricao, &preco)
+		var preco int64
+		err = db.QueryRow(`SELECT nome, descricao, preco_centavos FROM produtos WHERE id = ?`, 1).Scan(&nome, &descricao, &preco)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -123,8 +194,8 @@ func TestAtualizarProduto(t *testing.T) {
 		if nome != "Pizza Pepperoni" {
 			t.Errorf("expected produto name to be 'Pizza Pepperoni', got '%s'", nome)
 		}
-		if preco != 35 {
-			t.Errorf("expected produto price to be 35, got %f", preco)
+		if preco != 3500 {
+			t.Errorf("expected produto price to be 3500 cents, got %d", preco)
 		}
 	})
 }
@@ -136,14 +207,14 @@ func TestDeletarProduto(t *testing.T) {
 	repo := &ProdutoDbMock{Db: db}
 
 	// Insert sample data
-	_, err := db.Exec(`INSERT INTO produtos (categoria_id, nome, descricao, preco, tempo_de_preparo_minutos) VALUES (?, ?, ?, ?, ?)`,
-		1, "Pizza Margherita", "Classic pizza with tomato, mozzarella, and basil", 29.99, 15)
+	_, err := db.Exec(`INSERT INTO produtos (categoria_id, nome, descricao, preco_centavos, tempo_de_preparo_minutos) VALUES (?, ?, ?, ?, ?)`,
+		1, "Pizza Margherita", "Classic pizza with tomato, mozzarella, and basil", 2999, 15)
 	if err != nil {
 		t.Fatalf("failed to insert sample produto: %v", err)
 	}
 
 	t.Run("Delete produto by ID", func(t *testing.T) {
-		err := repo.DeletarProduto(1)
+		err := repo.DeletarProduto(context.Background(), 1)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -0,0 +1,50 @@
+package categoria_usecase
+
+import (
+	"context"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+	"github.com/gomesmatheus/tc-pedido/infraestructure/persistence"
+)
+
+var descricaoObrigatoria = entity.ErroCampo{Campo: "descricao", Mensagem: "obrigatório"}
+
+type categoriaUseCases struct {
+	database persistence.CategoriaRepository
+}
+
+func NewCategoriaUseCases(categoriaRepository persistence.CategoriaRepository) *categoriaUseCases {
+	return &categoriaUseCases{
+		database: categoriaRepository,
+	}
+}
+
+func (usecase *categoriaUseCases) CriarCategoria(ctx context.Context, c entity.Categoria) (entity.Categoria, error) {
+	if c.Descricao == "" {
+		return c, entity.ErrCategoriaInvalida.ComCampos(descricaoObrigatoria)
+	}
+
+	return usecase.database.CriarCategoria(ctx, c)
+}
+
+func (usecase *categoriaUseCases) RecuperarCategorias(ctx context.Context) ([]entity.Categoria, error) {
+	return usecase.database.RecuperarCategorias(ctx)
+}
+
+func (usecase *categoriaUseCases) RecuperarCategoria(ctx context.Context, id int) (entity.Categoria, error) {
+	return usecase.database.RecuperarCategoria(ctx, id)
+}
+
+func (usecase *categoriaUseCases) AtualizarCategoria(ctx context.Context, id int, c entity.Categoria) error {
+	if c.Descricao == "" {
+		return entity.ErrCategoriaInvalida.ComCampos(descricaoObrigatoria)
+	}
+
+	return usecase.database.AtualizarCategoria(ctx, id, c)
+}
+
+// DeletarCategoria retorna ErrCategoriaEmUso quando ainda há produtos na categoria; a
+// verificação e a remoção acontecem juntas no repositório.
+func (usecase *categoriaUseCases) DeletarCategoria(ctx context.Context, id int) error {
+	return usecase.database.DeletarCategoria(ctx, id)
+}
@@ -0,0 +1,99 @@
+package categoria_usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+type MockCategoriaRepository struct {
+	CriarCategoriaMock      func(c entity.Categoria) (entity.Categoria, error)
+	RecuperarCategoriasMock func() ([]entity.Categoria, error)
+	RecuperarCategoriaMock  func(id int) (entity.Categoria, error)
+	AtualizarCategoriaMock  func(id int, c entity.Categoria) error
+	DeletarCategoriaMock    func(id int) error
+}
+
+func (m *MockCategoriaRepository) CriarCategoria(ctx context.Context, c entity.Categoria) (entity.Categoria, error) {
+	return m.CriarCategoriaMock(c)
+}
+
+func (m *MockCategoriaRepository) RecuperarCategorias(ctx context.Context) ([]entity.Categoria, error) {
+	return m.RecuperarCategoriasMock()
+}
+
+func (m *MockCategoriaRepository) RecuperarCategoria(ctx context.Context, id int) (entity.Categoria, error) {
+	return m.RecuperarCategoriaMock(id)
+}
+
+func (m *MockCategoriaRepository) AtualizarCategoria(ctx context.Context, id int, c entity.Categoria) error {
+	return m.AtualizarCategoriaMock(id, c)
+}
+
+func (m *MockCategoriaRepository) DeletarCategoria(ctx context.Context, id int) error {
+	return m.DeletarCategoriaMock(id)
+}
+
+func TestCategoriaUseCases(t *testing.T) {
+	deletadas := 0
+	mockRepo := &MockCategoriaRepository{
+		CriarCategoriaMock: func(c entity.Categoria) (entity.Categoria, error) {
+			c.Id = 5
+			return c, nil
+		},
+		AtualizarCategoriaMock: func(id int, c entity.Categoria) error {
+			return nil
+		},
+		DeletarCategoriaMock: func(id int) error {
+			if id == 1 {
+				return entity.ErrCategoriaEmUso
+			}
+			deletadas++
+			return nil
+		},
+	}
+
+	usecase := NewCategoriaUseCases(mockRepo)
+
+	t.Run("CriarCategoria - Valid Category", func(t *testing.T) {
+		categoria, err := usecase.CriarCategoria(context.Background(), entity.Categoria{Descricao: "Combo"})
+		if err != nil || categoria.Id != 5 {
+			t.Errorf("expected categoria 5 and no error, got %+v, %v", categoria, err)
+		}
+	})
+
+	t.Run("CriarCategoria - Empty Descricao", func(t *testing.T) {
+		_, err := usecase.CriarCategoria(context.Background(), entity.Categoria{})
+		if !errors.Is(err, entity.ErrCategoriaInvalida) {
+			t.Errorf("expected ErrCategoriaInvalida, got %v", err)
+		}
+	})
+
+	t.Run("AtualizarCategoria - Empty Descricao", func(t *testing.T) {
+		err := usecase.AtualizarCategoria(context.Background(), 1, entity.Categoria{})
+		if !errors.Is(err, entity.ErrCategoriaInvalida) {
+			t.Errorf("expected ErrCategoriaInvalida, got %v", err)
+		}
+	})
+
+	t.Run("DeletarCategoria - Category In Use", func(t *testing.T) {
+		err := usecase.DeletarCategoria(context.Background(), 1)
+		if !errors.Is(err, entity.ErrCategoriaEmUso) {
+			t.Errorf("expected ErrCategoriaEmUso, got %v", err)
+		}
+		if deletadas != 0 {
+			t.Errorf("expected no deletion, got %d", deletadas)
+		}
+	})
+
+	t.Run("DeletarCategoria - Empty Category", func(t *testing.T) {
+		if err := usecase.DeletarCategoria(context.Background(), 2); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if deletadas != 1 {
+			t.Errorf("expected one deletion, got %d", deletadas)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package idempotencia_usecase
+
+import (
+	"context"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+	"github.com/gomesmatheus/tc-pedido/infraestructure/persistence"
+)
+
+type idempotenciaUseCases struct {
+	database persistence.IdempotenciaRepository
+}
+
+func NewIdempotenciaUseCases(idempotenciaRepository persistence.IdempotenciaRepository) *idempotenciaUseCases {
+	return &idempotenciaUseCases{
+		database: idempotenciaRepository,
+	}
+}
+
+// Iniciar reserva a chave para a requisição identificada por hash. Devolve nil quando a
+// chave é nova e a requisição deve ser processada, ou a resposta gravada quando a mesma
+// requisição já foi concluída. Uma reserva sem resposta há mais de
+// entity.PrazoReservaIdempotencia é tratada como abandonada e assumida por esta requisição.
+func (usecase *idempotenciaUseCases) Iniciar(ctx context.Context, chave string, hash string) (*entity.Idempotencia, error) {
+	if chave == "" || len(chave) > entity.TamanhoMaximoChaveIdempotencia {
+		return nil, entity.ErrChaveIdempotenciaInvalida
+	}
+
+	registro, nova, err := usecase.database.Reservar(ctx, chave, hash, entity.PrazoReservaIdempotencia)
+	if err != nil {
+		return nil, err
+	}
+	if nova {
+		return nil, nil
+	}
+	if registro.Hash != hash {
+		return nil, entity.ErrChaveIdempotenciaReutilizada
+	}
+	if !registro.Concluida() {
+		return nil, entity.ErrRequisicaoEmAndamento
+	}
+	return &registro, nil
+}
+
+func (usecase *idempotenciaUseCases) Concluir(ctx context.Context, chave string, status int, contentType string, corpo []byte) error {
+	return usecase.database.Concluir(ctx, chave, status, contentType, corpo)
+}
+
+// Liberar descarta uma reserva cuja requisição falhou, permitindo que o cliente tente de novo.
+func (usecase *idempotenciaUseCases) Liberar(ctx context.Context, chave string) error {
+	return usecase.database.Liberar(ctx, chave)
+}
+
+// Expirar apaga as chaves criadas há mais de entity.ValidadeIdempotencia e devolve quantas foram
+// removidas.
+func (usecase *idempotenciaUseCases) Expirar(ctx context.Context) (int64, error) {
+	return usecase.database.Expirar(ctx, entity.ValidadeIdempotencia)
+}
@@ -0,0 +1,112 @@
+package idempotencia_usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
+
+type MockIdempotenciaRepository struct {
+	ReservarMock func(chave string, hash string, abandono time.Duration) (entity.Idempotencia, bool, error)
+	ConcluirMock func(chave string, status int, contentType string, corpo []byte) error
+	LiberarMock  func(chave string) error
+	ExpirarMock  func(validade time.Duration) (int64, error)
+}
+
+func (m *MockIdempotenciaRepository) Reservar(ctx context.Context, chave string, hash string, abandono time.Duration) (entity.Idempotencia, bool, error) {
+	return m.ReservarMock(chave, hash, abandono)
+}
+
+func (m *MockIdempotenciaRepository) Concluir(ctx context.Context, chave string, status int, contentType string, corpo []byte) error {
+	return m.ConcluirMock(chave, status, contentType, corpo)
+}
+
+func (m *MockIdempotenciaRepository) Liberar(ctx context.Context, chave string) error {
+	return m.LiberarMock(chave)
+}
+
+func (m *MockIdempotenciaRepository) Expirar(ctx context.Context, validade time.Duration) (int64, error) {
+	return m.ExpirarMock(validade)
+}
+
+func TestIniciar(t *testing.T) {
+	registros := map[string]entity.Idempotencia{
+		"concluida": {Chave: "concluida", Hash: "h1", Status: 201, ContentType: "application/json", Corpo: []byte(`{"id":1}`)},
+		"andamento": {Chave: "andamento", Hash: "h1"},
+	}
+	mockRepo := &MockIdempotenciaRepository{
+		ReservarMock: func(chave string, hash string, abandono time.Duration) (entity.Idempotencia, bool, error) {
+			if abandono != entity.PrazoReservaIdempotencia {
+				t.Errorf("expected abandonment window %v, got %v", entity.PrazoReservaIdempotencia, abandono)
+			}
+			if chave == "falha" {
+				return entity.Idempotencia{}, false, errors.New("db down")
+			}
+			if r, ok := registros[chave]; ok {
+				return r, false, nil
+			}
+			return entity.Idempotencia{Chave: chave, Hash: hash}, true, nil
+		},
+	}
+	usecase := NewIdempotenciaUseCases(mockRepo)
+
+	tests := []struct {
+		name           string
+		chave          string
+		hash           string
+		expectedErr    error
+		expectedReplay bool
+	}{
+		{name: "New key", chave: "nova", hash: "h1"},
+		{name: "Replay completed request", chave: "concluida", hash: "h1", expectedReplay: true},
+		{name: "Key reused with another body", chave: "concluida", hash: "h2", expectedErr: entity.ErrChaveIdempotenciaReutilizada},
+		{name: "Request still in progress", chave: "andamento", hash: "h1", expectedErr: entity.ErrRequisicaoEmAndamento},
+		{name: "Empty key", chave: "", hash: "h1", expectedErr: entity.ErrChaveIdempotenciaInvalida},
+		{name: "Key too long", chave: strings.Repeat("a", entity.TamanhoMaximoChaveIdempotencia+1), hash: "h1", expectedErr: entity.ErrChaveIdempotenciaInvalida},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registro, err := usecase.Iniciar(context.Background(), tt.chave, tt.hash)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if (registro != nil) != tt.expectedReplay {
+				t.Errorf("expected replay %v, got %+v", tt.expectedReplay, registro)
+			}
+			if tt.expectedReplay && (registro.Status != 201 || string(registro.Corpo) != `{"id":1}`) {
+				t.Errorf("unexpected stored response: %+v", registro)
+			}
+		})
+	}
+
+	t.Run("Repository error", func(t *testing.T) {
+		if _, err := usecase.Iniciar(context.Background(), "falha", "h1"); err == nil {
+			t.Errorf("expected the repository error")
+		}
+	})
+}
+
+func TestExpirar(t *testing.T) {
+	mockRepo := &MockIdempotenciaRepository{
+		ExpirarMock: func(validade time.Duration) (int64, error) {
+			if validade != entity.ValidadeIdempotencia {
+				t.Errorf("expected validity %v, got %v", entity.ValidadeIdempotencia, validade)
+			}
+			return 3, nil
+		},
+	}
+	usecase := NewIdempotenciaUseCases(mockRepo)
+
+	removidas, err := usecase.Expirar(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removidas != 3 {
+		t.Errorf("expected 3 removed keys, got %d", removidas)
+	}
+}
@@ -1,16 +1,41 @@
 package usecase
 
-import "github.com/gomesmatheus/tc-pedido/domain/entity"
+import (
+	"context"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+)
 
 type ProdutoUseCases interface {
-	CriarProduto(p entity.Produto) (entity.Produto, error)
-	RecuperarProdutos(categoriaId int) ([]entity.Produto, error)
-	AtualizarProduto(id int, p entity.Produto) error
-	DeletarProduto(id int) error
+	CriarProduto(ctx context.Context, p entity.Produto) (entity.Produto, error)
+	RecuperarProdutos(ctx context.Context, categoriaId int) ([]entity.Produto, error)
+	RecuperarTodosProdutos(ctx context.Context) ([]entity.Produto, error)
+	RecuperarProduto(ctx context.Context, id int) (entity.Produto, error)
+	AtualizarProduto(ctx context.Context, id int, p entity.Produto) error
+	DeletarProduto(ctx context.Context, id int) error
+}
+
+type CategoriaUseCases interface {
+	CriarCategoria(ctx context.Context, c entity.Categoria) (entity.Categoria, error)
+	RecuperarCategorias(ctx context.Context) ([]entity.Categoria, error)
+	RecuperarCategoria(ctx context.Context, id int) (entity.Categoria, error)
+	AtualizarCategoria(ctx context.Context, id int, c entity.Categoria) error
+	DeletarCategoria(ctx context.Context, id int) error
 }
 
 type PedidoUseCases interface {
-	CriarPedido(entity.Pedido) (entity.Pedido, error)
-	RecuperarPedidos() ([]entity.Pedido, error)
-	AtualizarStatus(id int, status string) error
+	CriarPedido(ctx context.Context, p entity.Pedido) (entity.Pedido, error)
+	RecuperarPedidos(ctx context.Context, filtro entity.FiltroPedidos) (entity.PaginaPedidos, error)
+	RecuperarPedido(ctx context.Context, id int) (entity.Pedido, error)
+	RecuperarFilaCozinha(ctx context.Context) ([]entity.Pedido, error)
+	AtualizarStatus(ctx context.Context, id int, status entity.StatusPedido, responsavel string) error
+	ConfirmarPagamento(ctx context.Context, id int, aprovado bool) error
+	CancelarPedido(ctx context.Context, id int, motivo string, canceladoPor string) (entity.Cancelamento, error)
+	RecuperarHistorico(ctx context.Context, id int) ([]entity.HistoricoPedido, error)
+}
+
+type IdempotenciaUseCases interface {
+	Iniciar(ctx context.Context, chave string, hash string) (*entity.Idempotencia, error)
+	Concluir(ctx context.Context, chave string, status int, contentType string, corpo []byte) error
+	Liberar(ctx context.Context, chave string) error
 }
@@ -1,52 +1,409 @@
 package pedido_usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
-	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gomesmatheus/tc-pedido/domain/entity"
+	"github.com/gomesmatheus/tc-pedido/infraestructure/eventos"
+	"github.com/gomesmatheus/tc-pedido/infraestructure/gateway"
 	"github.com/gomesmatheus/tc-pedido/infraestructure/persistence"
 )
 
 type pedidoUseCases struct {
-	database persistence.PedidoRepository
+	database   persistence.PedidoRepository
+	produtos   persistence.ProdutoRepository
+	clientes   gateway.ClienteGateway
+	pagamentos gateway.PagamentoGateway
+	eventos    eventos.Publicador
 }
 
-func NewPedidoUseCases(pedidoRepository persistence.PedidoRepository) *pedidoUseCases {
+// NewPedidoUseCases recebe o publicador que é avisado de cada alteração de status ou de
+// pagamento gravada.
+func NewPedidoUseCases(pedidoRepository persistence.PedidoRepository, produtoRepository persistence.ProdutoRepository, clienteGateway gateway.ClienteGateway, pagamentoGateway gateway.PagamentoGateway, publicador eventos.Publicador) *pedidoUseCases {
 	return &pedidoUseCases{
-		database: pedidoRepository,
+		database:   pedidoRepository,
+		produtos:   produtoRepository,
+		clientes:   clienteGateway,
+		pagamentos: pagamentoGateway,
+		eventos:    publicador,
 	}
 }
 
-func (usecase *pedidoUseCases) CriarPedido(p entity.Pedido) (entity.Pedido, error) {
-	if !validarCliente(p.Cpf) {
-		return p, fmt.Errorf("Client with CPF %d is not registered", p.Cpf)
+func (usecase *pedidoUseCases) CriarPedido(ctx context.Context, p entity.Pedido) (entity.Pedido, error) {
+	if campos := validarItens(p); len(campos) > 0 {
+		return p, entity.ErrPedidoInvalido.ComCampos(campos...)
 	}
 
-	return usecase.database.CriarPedido(p)
+	cadastrado, err := usecase.clientes.ClienteCadastrado(ctx, p.Cpf)
+	if err != nil {
+		return p, err
+	}
+	if !cadastrado {
+		return p, entity.ErrClienteNaoCadastrado.ComCampos(entity.ErroCampo{Campo: "cpf", Mensagem: fmt.Sprintf("CPF %d não cadastrado", p.Cpf)})
+	}
+
+	var inexistentes []entity.ErroCampo
+	for i, pp := range p.Produtos {
+		produto, err := usecase.produtos.RecuperarProduto(ctx, pp.ProdutoId)
+		if errors.Is(err, entity.ErrProdutoNaoEncontrado) {
+			inexistentes = append(inexistentes, entity.ErroCampo{Campo: fmt.Sprintf("produtos[%d].produto_id", i), Mensagem: fmt.Sprintf("produto %d não encontrado", pp.ProdutoId)})
+			continue
+		}
+		if err != nil {
+			return p, fmt.Errorf("erro ao recuperar produto %d: %w", pp.ProdutoId, err)
+		}
+		p.Produtos[i].PrecoUnitario = produto.Preco
+		if produto.TempoDePreparo > p.TempoDePreparo {
+			p.TempoDePreparo = produto.TempoDePreparo
+		}
+	}
+	if len(inexistentes) > 0 {
+		return p, fmt.Errorf("%w: %w", entity.ErrPedidoInvalido.ComCampos(inexistentes...), entity.ErrProdutoNaoEncontrado)
+	}
+	if err := p.CalcularTotal(); err != nil {
+		return p, err
+	}
+
+	p, err = usecase.database.CriarPedido(ctx, p)
+	if err != nil {
+		return p, err
+	}
+	usecase.publicar(p.Id, entity.HistoricoPedido{
+		Evento:            entity.EventoCriacao,
+		Status:            p.Status,
+		PagamentoAprovado: p.PagamentoAprovado,
+		Responsavel:       entity.ResponsavelCliente,
+		RegistradoEm:      p.CriadoEm,
+	})
+
+	fila, err := usecase.recalcularPrevisoes(ctx)
+	if err != nil {
+		fmt.Println("Erro ao recalcular previsões da fila de preparo", err)
+		return p, nil
+	}
+	for _, f := range fila {
+		if f.Id == p.Id {
+			p.PrevisaoPronto = f.PrevisaoPronto
+		}
+	}
+
+	return p, nil
 }
-func (usecase *pedidoUseCases) RecuperarPedidos() ([]entity.Pedido, error) {
-	return usecase.database.RecuperarPedidos()
+
+// validarItens confere cada linha do pedido antes de consultar o catálogo, apontando
+// todas as linhas com problema de uma vez.
+func validarItens(p entity.Pedido) []entity.ErroCampo {
+	if len(p.Produtos) == 0 {
+		return []entity.ErroCampo{{Campo: "produtos", Mensagem: "o pedido deve ter ao menos um item"}}
+	}
+
+	var campos []entity.ErroCampo
+	for i, pp := range p.Produtos {
+		if pp.ProdutoId <= 0 {
+			campos = append(campos, entity.ErroCampo{Campo: fmt.Sprintf("produtos[%d].produto_id", i), Mensagem: "obrigatório"})
+		}
+		if pp.Quantidade < 1 || pp.Quantidade > entity.QuantidadeMaximaItem {
+			campos = append(campos, entity.ErroCampo{Campo: fmt.Sprintf("produtos[%d].quantidade", i), Mensagem: fmt.Sprintf("deve estar entre 1 e %d", entity.QuantidadeMaximaItem)})
+		}
+	}
+	return campos
 }
 
-func (usecase *pedidoUseCases) AtualizarStatus(id int, status string) error {
-	return usecase.database.AtualizarStatus(id, status)
+// RecuperarPedidos lista uma página de pedidos. Um pedido além do limite é buscado para
+// saber se existe próxima página; nesse caso o cursor aponta para o último pedido devolvido.
+func (usecase *pedidoUseCases) RecuperarPedidos(ctx context.Context, filtro entity.FiltroPedidos) (entity.PaginaPedidos, error) {
+	if filtro.Ordenacao == "" {
+		filtro.Ordenacao = entity.OrdenarPorData
+	}
+	if filtro.Limite == 0 {
+		filtro.Limite = entity.LimitePadraoPedidos
+	}
+	if campos := filtro.Validar(); len(campos) > 0 {
+		return entity.PaginaPedidos{}, entity.ErrFiltroInvalido.ComCampos(campos...)
+	}
+
+	consulta := filtro
+	consulta.Limite++
+	pedidos, err := usecase.database.RecuperarPedidos(ctx, consulta)
+	if err != nil {
+		return entity.PaginaPedidos{}, err
+	}
+
+	pagina := entity.PaginaPedidos{Pedidos: pedidos}
+	if len(pedidos) > filtro.Limite {
+		pagina.Pedidos = pedidos[:filtro.Limite]
+		pagina.ProximoCursor = entity.NovoCursorPedidos(filtro.Ordenacao, pagina.Pedidos[filtro.Limite-1]).Codificar()
+	}
+	if pagina.Pedidos == nil {
+		pagina.Pedidos = []entity.Pedido{}
+	}
+
+	return pagina, nil
+}
+
+func (usecase *pedidoUseCases) RecuperarPedido(ctx context.Context, id int) (entity.Pedido, error) {
+	return usecase.database.RecuperarPedido(ctx, id)
 }
 
-func validarCliente(cpf int64) bool {
-	// Define the service URL and endpoint
-	baseURL := "http://svc-cliente-app"
-	port := 80
-	endpoint := fmt.Sprintf("%s:%d/cliente/%d", baseURL, port, cpf)
+func (usecase *pedidoUseCases) RecuperarFilaCozinha(ctx context.Context) ([]entity.Pedido, error) {
+	return usecase.database.RecuperarFilaCozinha(ctx)
+}
+
+// AtualizarStatus aplica uma transição de status. O responsável é opcional e fica
+// registrado no histórico do pedido.
+func (usecase *pedidoUseCases) AtualizarStatus(ctx context.Context, id int, status entity.StatusPedido, responsavel string) error {
+	if !status.Valido() {
+		return entity.ErrStatusInvalido.ComCampos(entity.ErroCampo{Campo: "status", Mensagem: fmt.Sprintf("%q não é um status de pedido", status)})
+	}
+	if status == entity.StatusCancelado {
+		return entity.ErrCancelamentoViaStatus
+	}
+	responsavel = strings.TrimSpace(responsavel)
+	if utf8.RuneCountInString(responsavel) > entity.TamanhoMaximoResponsavel {
+		return entity.ErrPedidoInvalido.ComCampos(entity.ErroCampo{Campo: "responsavel", Mensagem: fmt.Sprintf("deve ter no máximo %d caracteres", entity.TamanhoMaximoResponsavel)})
+	}
 
-	// Create an HTTP GET request
-	resp, err := http.Get(endpoint)
+	statusAtual, err := usecase.database.RecuperarStatus(ctx, id)
 	if err != nil {
-		log.Fatalf("Failed to call cliente-app: %v", err)
+		return err
+	}
+
+	if !statusAtual.PodeTransicionarPara(status) {
+		return fmt.Errorf("%w: de %q para %q", entity.ErrTransicaoStatusInvalida, statusAtual, status)
 	}
-	defer resp.Body.Close()
 
-	fmt.Println(resp.StatusCode)
-	return resp.StatusCode == 200
+	return usecase.gravarStatus(ctx, id, statusAtual, status, responsavel)
+}
+
+// ConfirmarPagamento aplica o resultado de uma notificação de pagamento. Um pagamento
+// aprovado libera o pedido para preparação. Um recusado é registrado e o pedido continua
+// em Recebido, aguardando uma nova tentativa; cancelar é uma ação explícita de
+// CancelarPedido. Um pagamento aprovado depois do cancelamento é gravado
+// e devolvido ao cliente com um reembolso do total. Notificações
+// repetidas com o mesmo resultado não alteram o pedido. As alterações entram no histórico
+// em nome do serviço de pagamentos.
+func (usecase *pedidoUseCases) ConfirmarPagamento(ctx context.Context, id int, aprovado bool) error {
+	status, err := usecase.database.RecuperarStatus(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	pago, err := usecase.database.RecuperarPagamento(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if aprovado {
+		if status == entity.StatusCancelado {
+			if pago {
+				return nil
+			}
+			return usecase.reembolsarPagamentoCancelado(ctx, id)
+		}
+		if !pago {
+			h, alterado, err := usecase.database.AtualizarPagamento(ctx, id, true, entity.ResponsavelPagamento)
+			if err != nil {
+				return err
+			}
+			if !alterado {
+				// Outra entrega da mesma notificação aplicou o pagamento e segue com o pedido.
+				return nil
+			}
+			usecase.publicar(id, h)
+		}
+		if status == entity.StatusRecebido {
+			return usecase.gravarStatus(ctx, id, status, entity.StatusEmPreparacao, entity.ResponsavelPagamento)
+		}
+		return nil
+	}
+
+	if pago {
+		return entity.ErrPagamentoJaProcessado
+	}
+	h, alterado, err := usecase.database.AtualizarPagamento(ctx, id, false, entity.ResponsavelPagamento)
+	if err != nil {
+		return err
+	}
+	if alterado {
+		usecase.publicar(id, h)
+	}
+	return nil
+}
+
+// CancelarPedido cancela um pedido que ainda não ficou pronto, registrando o motivo e quem
+// cancelou. Se o pagamento já tinha sido aprovado, o reembolso do total é solicitado depois
+// que o cancelamento é gravado; se a solicitação falhar, o cancelamento é mantido e o
+// reembolso fica pendente até ser reenviado por ReenviarReembolsos.
+func (usecase *pedidoUseCases) CancelarPedido(ctx context.Context, id int, motivo string, canceladoPor string) (entity.Cancelamento, error) {
+	c := entity.Cancelamento{
+		PedidoId:     id,
+		Motivo:       strings.TrimSpace(motivo),
+		CanceladoPor: strings.TrimSpace(canceladoPor),
+		CanceladoEm:  time.Now(),
+	}
+	if campos := c.Validar(); len(campos) > 0 {
+		return c, entity.ErrCancelamentoInvalido.ComCampos(campos...)
+	}
+
+	pedido, err := usecase.database.RecuperarPedido(ctx, id)
+	if err != nil {
+		return c, err
+	}
+	if !pedido.Status.PodeSerCancelado() {
+		return c, fmt.Errorf("%w: pedido está %q", entity.ErrCancelamentoNaoPermitido, pedido.Status)
+	}
+	if pedido.PagamentoAprovado {
+		c.Reembolso = &entity.Reembolso{Valor: pedido.Total, Status: entity.ReembolsoPendente}
+	}
+
+	if err := usecase.database.CancelarPedido(ctx, c, pedido.Status); err != nil {
+		return c, err
+	}
+	usecase.publicar(id, entity.HistoricoPedido{
+		Evento:            entity.EventoCancelamento,
+		Status:            entity.StatusCancelado,
+		PagamentoAprovado: pedido.PagamentoAprovado,
+		Responsavel:       c.CanceladoPor,
+		Motivo:            c.Motivo,
+		RegistradoEm:      c.CanceladoEm,
+	})
+	if _, err := usecase.recalcularPrevisoes(ctx); err != nil {
+		fmt.Println("Erro ao recalcular previsões da fila de preparo", err)
+	}
+
+	if c.Reembolso != nil {
+		solicitacao := entity.SolicitacaoReembolso{PedidoId: id, Valor: c.Reembolso.Valor, Motivo: c.Motivo}
+		if usecase.solicitarReembolso(context.WithoutCancel(ctx), solicitacao) {
+			c.Reembolso.Status = entity.ReembolsoSolicitado
+		}
+	}
+	return c, nil
+}
+
+// reembolsarPagamentoCancelado grava a aprovação que chegou depois do cancelamento do pedido
+// e solicita a devolução do total.
+func (usecase *pedidoUseCases) reembolsarPagamentoCancelado(ctx context.Context, id int) error {
+	pedido, err := usecase.database.RecuperarPedido(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	h, solicitacao, err := usecase.database.AprovarPagamentoCancelado(ctx, id, pedido.Total, entity.ResponsavelPagamento)
+	if errors.Is(err, entity.ErrPagamentoJaProcessado) {
+		// Outra entrega da mesma notificação já gravou o pagamento e o reembolso.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	usecase.publicar(id, h)
+	usecase.solicitarReembolso(context.WithoutCancel(ctx), solicitacao)
+	return nil
+}
+
+func (usecase *pedidoUseCases) RecuperarHistorico(ctx context.Context, id int) ([]entity.HistoricoPedido, error) {
+	return usecase.database.RecuperarHistorico(ctx, id)
+}
+
+// ReenviarReembolsos tenta de novo os reembolsos que ficaram pendentes e devolve quantos foram
+// aceitos pelo serviço de pagamentos. Os que continuam pendentes resultam em erro, para que a
+// falha apareça no log da tarefa periódica.
+func (usecase *pedidoUseCases) ReenviarReembolsos(ctx context.Context) (int, error) {
+	pendentes, err := usecase.database.RecuperarReembolsosPendentes(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	solicitados := 0
+	for _, s := range pendentes {
+		if usecase.solicitarReembolso(ctx, s) {
+			solicitados++
+		}
+	}
+	if solicitados < len(pendentes) {
+		return solicitados, fmt.Errorf("%w: %d de %d reembolsos continuam pendentes", entity.ErrPagamentoIndisponivel, len(pendentes)-solicitados, len(pendentes))
+	}
+	return solicitados, nil
+}
+
+// solicitarReembolso não desfaz o cancelamento já gravado: uma falha deixa o reembolso
+// pendente, e ReenviarReembolsos o envia de novo.
+func (usecase *pedidoUseCases) solicitarReembolso(ctx context.Context, solicitacao entity.SolicitacaoReembolso) bool {
+	if err := usecase.pagamentos.SolicitarReembolso(ctx, solicitacao); err != nil {
+		fmt.Println("Erro ao solicitar reembolso do pedido", solicitacao.PedidoId, err)
+		return false
+	}
+
+	if err := usecase.database.AtualizarReembolso(ctx, solicitacao.PedidoId, entity.ReembolsoSolicitado); err != nil {
+		fmt.Println("Erro ao registrar reembolso solicitado do pedido", solicitacao.PedidoId, err)
+		return false
+	}
+	return true
+}
+
+// gravarStatus persiste o novo status e atualiza as previsões de entrega, já que a saída
+// de um pedido da fila de preparo antecipa os pedidos que estão atrás dele. A gravação
+// falha se o pedido não estiver mais em statusAtual, o status validado pelo chamador.
+func (usecase *pedidoUseCases) gravarStatus(ctx context.Context, id int, statusAtual entity.StatusPedido, status entity.StatusPedido, responsavel string) error {
+	h, err := usecase.database.AtualizarStatus(ctx, id, statusAtual, status, responsavel)
+	if err != nil {
+		return err
+	}
+	usecase.publicar(id, h)
+
+	var prontos []entity.Pedido
+	if status == entity.StatusPronto {
+		agora := time.Now()
+		prontos = append(prontos, entity.Pedido{Id: id, PrevisaoPronto: &agora})
+	}
+	if _, err = usecase.recalcularPrevisoes(ctx, prontos...); err != nil {
+		fmt.Println("Erro ao recalcular previsões da fila de preparo", err)
+	}
+
+	return nil
+}
+
+// publicar avisa os assinantes de uma alteração que já foi gravada.
+func (usecase *pedidoUseCases) publicar(id int, h entity.HistoricoPedido) {
+	usecase.eventos.Publicar(entity.NovaAlteracaoPedido(id, h))
+}
+
+// recalcularPrevisoes estima de novo a fila de preparo e grava as previsões de uma vez,
+// junto com as de prontos, os pedidos que acabaram de sair da fila.
+func (usecase *pedidoUseCases) recalcularPrevisoes(ctx context.Context, prontos ...entity.Pedido) ([]entity.Pedido, error) {
+	fila, err := usecase.database.RecuperarFilaPreparo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	estimarPrevisoes(fila, time.Now())
+	if err := usecase.database.AtualizarPrevisoes(ctx, append(prontos, fila...)); err != nil {
+		return nil, err
+	}
+
+	return fila, nil
+}
+
+// estimarPrevisoes considera que a cozinha prepara a fila na ordem de chegada: cada pedido
+// fica pronto depois do anterior, somando o seu próprio tempo de preparo. Um pedido já em
+// preparação mantém a previsão anterior quando ela é mais cedo que a recalculada.
+func estimarPrevisoes(fila []entity.Pedido, agora time.Time) {
+	cursor := agora
+	for i := range fila {
+		previsao := cursor.Add(time.Duration(fila[i].TempoDePreparo) * time.Minute)
+		anterior := fila[i].PrevisaoPronto
+		if fila[i].Status == entity.StatusEmPreparacao && anterior != nil && anterior.After(agora) && anterior.Before(previsao) {
+			previsao = *anterior
+		}
+
+		fila[i].PrevisaoPronto = &previsao
+		if previsao.After(cursor) {
+			cursor = previsao
+		}
+	}
 }
@@ -0,0 +1,774 @@
+package pedido_usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomesmatheus/tc-pedido/domain/entity"
+	"github.com/gomesmatheus/tc-pedido/infraestructure/eventos"
+	"github.com/gomesmatheus/tc-pedido/infraestructure/gateway"
+	"github.com/gomesmatheus/tc-pedido/infraestructure/persistence"
+)
+
+type MockPedidoRepository struct {
+	CriarPedidoMock          func(p entity.Pedido) (entity.Pedido, error)
+	RecuperarPedidosMock     func(filtro entity.FiltroPedidos) ([]entity.Pedido, error)
+	RecuperarPedidoMock      func(id int) (entity.Pedido, error)
+	RecuperarFilaCozinhaMock func() ([]entity.Pedido, error)
+	RecuperarStatusMock      func(id int) (entity.StatusPedido, error)
+	AtualizarStatusMock      func(id int, statusAtual entity.StatusPedido, status entity.StatusPedido, responsavel string) error
+	RecuperarPagamentoMock   func(id int) (bool, error)
+	AtualizarPagamentoMock   func(id int, status bool, responsavel string) (bool, error)
+	RecuperarFilaMock        func() ([]entity.Pedido, error)
+	AtualizarPrevisoesMock   func(fila []entity.Pedido) error
+	CancelarPedidoMock       func(c entity.Cancelamento, statusAtual entity.StatusPedido) error
+	AtualizarReembolsoMock   func(id int, status entity.StatusReembolso) error
+	AprovarCanceladoMock     func(id int, valor entity.Dinheiro, responsavel string) (entity.SolicitacaoReembolso, error)
+	ReembolsosPendentesMock  func() ([]entity.SolicitacaoReembolso, error)
+	RecuperarHistoricoMock   func(id int) ([]entity.HistoricoPedido, error)
+}
+
+func (m *MockPedidoRepository) CriarPedido(ctx context.Context, p entity.Pedido) (entity.Pedido, error) {
+	return m.CriarPedidoMock(p)
+}
+
+func (m *MockPedidoRepository) RecuperarPedidos(ctx context.Context, filtro entity.FiltroPedidos) ([]entity.Pedido, error) {
+	return m.RecuperarPedidosMock(filtro)
+}
+
+func (m *MockPedidoRepository) RecuperarPedido(ctx context.Context, id int) (entity.Pedido, error) {
+	return m.RecuperarPedidoMock(id)
+}
+
+func (m *MockPedidoRepository) RecuperarFilaCozinha(ctx context.Context) ([]entity.Pedido, error) {
+	return m.RecuperarFilaCozinhaMock()
+}
+
+func (m *MockPedidoRepository) RecuperarStatus(ctx context.Context, id int) (entity.StatusPedido, error) {
+	return m.RecuperarStatusMock(id)
+}
+
+func (m *MockPedidoRepository) AtualizarStatus(ctx context.Context, id int, statusAtual entity.StatusPedido, status entity.StatusPedido, responsavel string) (entity.HistoricoPedido, error) {
+	h := entity.HistoricoPedido{Evento: entity.EventoStatus, Status: status, Responsavel: responsavel}
+	return h, m.AtualizarStatusMock(id, statusAtual, status, responsavel)
+}
+
+func (m *MockPedidoRepository) RecuperarPagamento(ctx context.Context, id int) (bool, error) {
+	return m.RecuperarPagamentoMock(id)
+}
+
+func (m *MockPedidoRepository) AtualizarPagamento(ctx context.Context, id int, status bool, responsavel string) (entity.HistoricoPedido, bool, error) {
+	h := entity.HistoricoPedido{Evento: entity.EventoPagamento, PagamentoAprovado: status, Responsavel: responsavel}
+	alterado, err := m.AtualizarPagamentoMock(id, status, responsavel)
+	return h, alterado, err
+}
+
+type MockProdutoRepository struct {
+	persistence.ProdutoRepository
+	RecuperarProdutoMock func(id int) (entity.Produto, error)
+}
+
+func (m *MockProdutoRepository) RecuperarProduto(ctx context.Context, id int) (entity.Produto, error) {
+	return m.RecuperarProdutoMock(id)
+}
+
+func (m *MockPedidoRepository) RecuperarFilaPreparo(ctx context.Context) ([]entity.Pedido, error) {
+	return m.RecuperarFilaMock()
+}
+
+func (m *MockPedidoRepository) AtualizarPrevisoes(ctx context.Context, fila []entity.Pedido) error {
+	return m.AtualizarPrevisoesMock(fila)
+}
+
+func (m *MockPedidoRepository) CancelarPedido(ctx context.Context, c entity.Cancelamento, statusAtual entity.StatusPedido) error {
+	return m.CancelarPedidoMock(c, statusAtual)
+}
+
+func (m *MockPedidoRepository) AtualizarReembolso(ctx context.Context, id int, status entity.StatusReembolso) error {
+	return m.AtualizarReembolsoMock(id, status)
+}
+
+func (m *MockPedidoRepository) AprovarPagamentoCancelado(ctx context.Context, id int, valor entity.Dinheiro, responsavel string) (entity.HistoricoPedido, entity.SolicitacaoReembolso, error) {
+	h := entity.HistoricoPedido{Evento: entity.EventoPagamento, Status: entity.StatusCancelado, PagamentoAprovado: true, Responsavel: responsavel}
+	s, err := m.AprovarCanceladoMock(id, valor, responsavel)
+	return h, s, err
+}
+
+func (m *MockPedidoRepository) RecuperarReembolsosPendentes(ctx context.Context) ([]entity.SolicitacaoReembolso, error) {
+	return m.ReembolsosPendentesMock()
+}
+
+func (m *MockPedidoRepository) RecuperarHistorico(ctx context.Context, id int) ([]entity.HistoricoPedido, error) {
+	return m.RecuperarHistoricoMock(id)
+}
+
+func filaVazia() ([]entity.Pedido, error) {
+	return nil, nil
+}
+
+func ignorarPrevisoes(fila []entity.Pedido) error {
+	return nil
+}
+
+func TestCriarPedido(t *testing.T) {
+	mockRepo := &MockPedidoRepository{
+		CriarPedidoMock: func(p entity.Pedido) (entity.Pedido, error) {
+			p.Id = 1
+			return p, nil
+		},
+		RecuperarFilaMock: func() ([]entity.Pedido, error) {
+			return []entity.Pedido{{Id: 1, Status: entity.StatusRecebido, TempoDePreparo: 15}}, nil
+		},
+		AtualizarPrevisoesMock: ignorarPrevisoes,
+	}
+	mockProdutoRepo := &MockProdutoRepository{
+		RecuperarProdutoMock: func(id int) (entity.Produto, error) {
+			switch id {
+			case 1:
+				return entity.Produto{Id: 1, Preco: entity.Centavos(2500), TempoDePreparo: 15}, nil
+			case 2:
+				return entity.Produto{Id: 2, Preco: entity.Centavos(750), TempoDePreparo: 5}, nil
+			}
+			return entity.Produto{}, entity.ErrProdutoNaoEncontrado
+		},
+	}
+	cadastrado := &gateway.ClienteGatewayFake{Cadastrados: map[int64]bool{12345: true}}
+
+	t.Run("Compute total from the prices", func(t *testing.T) {
+		publicador := &eventos.PublicadorFake{}
+		usecase := NewPedidoUseCases(mockRepo, mockProdutoRepo, cadastrado, &gateway.PagamentoGatewayFake{}, publicador)
+		pedido, err := usecase.CriarPedido(context.Background(), entity.Pedido{Cpf: 12345, Produtos: []entity.ProdutoPedido{
+			{ProdutoId: 1, Quantidade: 2},
+			{ProdutoId: 2, Quantidade: 1, PrecoUnitario: entity.Centavos(1)},
+		}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if pedido.Produtos[0].Subtotal.Centavos != 5000 || pedido.Produtos[1].PrecoUnitario.Centavos != 750 {
+			t.Errorf("unexpected line values: %+v", pedido.Produtos)
+		}
+		if pedido.Total.Centavos != 5750 {
+			t.Errorf("expected total 57.5, got %v", pedido.Total)
+		}
+		if pedido.TempoDePreparo != 15 {
+			t.Errorf("expected tempo de preparo 15, got %d", pedido.TempoDePreparo)
+		}
+		if pedido.PrevisaoPronto == nil {
+			t.Errorf("expected previsao_pronto to be set")
+		}
+		if publicadas := publicador.Alteracoes(); len(publicadas) != 1 || publicadas[0].PedidoId != 1 || publicadas[0].Evento != entity.EventoCriacao {
+			t.Errorf("expected the creation to be published, got %+v", publicadas)
+		}
+	})
+
+	t.Run("Unknown produto", func(t *testing.T) {
+		usecase := NewPedidoUseCases(mockRepo, mockProdutoRepo, cadastrado, &gateway.PagamentoGatewayFake{}, &eventos.PublicadorFake{})
+		_, err := usecase.CriarPedido(context.Background(), entity.Pedido{Cpf: 12345, Produtos: []entity.ProdutoPedido{{ProdutoId: 9999, Quantidade: 1}}})
+		if !errors.Is(err, entity.ErrProdutoNaoEncontrado) {
+			t.Errorf("expected ErrProdutoNaoEncontrado, got %v", err)
+		}
+		var erroDominio *entity.ErroDominio
+		if !errors.As(err, &erroDominio) || erroDominio.Codigo != entity.ErrPedidoInvalido.Codigo || len(erroDominio.Campos) != 1 {
+			t.Errorf("expected ErrPedidoInvalido with the offending item, got %v", err)
+		}
+	})
+
+	t.Run("Every unknown produto is reported", func(t *testing.T) {
+		usecase := NewPedidoUseCases(mockRepo, mockProdutoRepo, cadastrado, &gateway.PagamentoGatewayFake{}, &eventos.PublicadorFake{})
+		_, err := usecase.CriarPedido(context.Background(), entity.Pedido{Cpf: 12345, Produtos: []entity.ProdutoPedido{
+			{ProdutoId: 9999, Quantidade: 1},
+			{ProdutoId: 1, Quantidade: 1},
+			{ProdutoId: 8888, Quantidade: 1},
+		}})
+		var erroDominio *entity.ErroDominio
+		if !errors.As(err, &erroDominio) || len(erroDominio.Campos) != 2 {
+			t.Fatalf("expected both missing produtos to be reported, got %v", err)
+		}
+		if erroDominio.Campos[0].Campo != "produtos[0].produto_id" || erroDominio.Campos[1].Campo != "produtos[2].produto_id" {
+			t.Errorf("unexpected fields: %+v", erroDominio.Campos)
+		}
+	})
+
+	t.Run("Invalid items", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			produtos       []entity.ProdutoPedido
+			expectedCampos []entity.ErroCampo
+		}{
+			{
+				name:           "No items",
+				expectedCampos: []entity.ErroCampo{{Campo: "produtos", Mensagem: "o pedido deve ter ao menos um item"}},
+			},
+			{
+				name:     "Zero, negative and over-limit quantities",
+				produtos: []entity.ProdutoPedido{{ProdutoId: 1, Quantidade: 0}, {ProdutoId: 1, Quantidade: 1}, {ProdutoId: 2, Quantidade: -3}, {ProdutoId: 2, Quantidade: entity.QuantidadeMaximaItem + 1}},
+				expectedCampos: []entity.ErroCampo{
+					{Campo: "produtos[0].quantidade", Mensagem: "deve estar entre 1 e 99"},
+					{Campo: "produtos[2].quantidade", Mensagem: "deve estar entre 1 e 99"},
+					{Campo: "produtos[3].quantidade", Mensagem: "deve estar entre 1 e 99"},
+				},
+			},
+			{
+				name:           "Missing produto",
+				produtos:       []entity.ProdutoPedido{{Quantidade: 1}},
+				expectedCampos: []entity.ErroCampo{{Campo: "produtos[0].produto_id", Mensagem: "obrigatório"}},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				usecase := NewPedidoUseCases(mockRepo, &MockProdutoRepository{}, &gateway.ClienteGatewayFake{}, &gateway.PagamentoGatewayFake{}, &eventos.PublicadorFake{})
+				_, err := usecase.CriarPedido(context.Background(), entity.Pedido{Cpf: 12345, Produtos: tt.produtos})
+
+				var erroDominio *entity.ErroDominio
+				if !errors.As(err, &erroDominio) || erroDominio.Codigo != entity.ErrPedidoInvalido.Codigo {
+					t.Fatalf("expected ErrPedidoInvalido, got %v", err)
+				}
+				if !reflect.DeepEqual(erroDominio.Campos, tt.expectedCampos) {
+					t.Errorf("expected %+v, got %+v", tt.expectedCampos, erroDominio.Campos)
+				}
+			})
+		}
+	})
+
+	t.Run("Registered cliente", func(t *testing.T) {
+		usecase := NewPedidoUseCases(mockRepo, mockProdutoRepo, &gateway.ClienteGatewayFake{Cadastrados: map[int64]bool{12345: true}}, &gateway.PagamentoGatewayFake{}, &eventos.PublicadorFake{})
+		pedido, err := usecase.CriarPedido(context.Background(), entity.Pedido{Cpf: 12345, Produtos: []entity.ProdutoPedido{{ProdutoId: 1, Quantidade: 1}}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if pedido.Id != 1 {
+			t.Errorf("expected pedido id 1, got %d", pedido.Id)
+		}
+	})
+
+	t.Run("Unregistered cliente", func(t *testing.T) {
+		usecase := NewPedidoUseCases(mockRepo, mockProdutoRepo, &gateway.ClienteGatewayFake{}, &gateway.PagamentoGatewayFake{}, &eventos.PublicadorFake{})
+		_, err := usecase.CriarPedido(context.Background(), entity.Pedido{Cpf: 12345, Produtos: []entity.ProdutoPedido{{ProdutoId: 1, Quantidade: 1}}})
+		if !errors.Is(err, entity.ErrClienteNaoCadastrado) {
+			t.Errorf("expected ErrClienteNaoCadastrado, got %v", err)
+		}
+	})
+
+	t.Run("Cliente service unavailable", func(t *testing.T) {
+		usecase := NewPedidoUseCases(mockRepo, mockProdutoRepo, &gateway.ClienteGatewayFake{Err: entity.ErrClienteIndisponivel}, &gateway.PagamentoGatewayFake{}, &eventos.PublicadorFake{})
+		_, err := usecase.CriarPedido(context.Background(), entity.Pedido{Cpf: 12345, Produtos: []entity.ProdutoPedido{{ProdutoId: 1, Quantidade: 1}}})
+		if !errors.Is(err, entity.ErrClienteIndisponivel) {
+			t.Errorf("expected ErrClienteIndisponivel, got %v", err)
+		}
+	})
+}
+
+func TestRecuperarPedidos(t *testing.T) {
+	criado := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pedidos := []entity.Pedido{{Id: 1, CriadoEm: criado}, {Id: 2, CriadoEm: criado}, {Id: 3, CriadoEm: criado}}
+
+	t.Run("Apply defaults and return a cursor when there is a next page", func(t *testing.T) {
+		var recebido entity.FiltroPedidos
+		mockRepo := &MockPedidoRepository{
+			RecuperarPedidosMock: func(filtro entity.FiltroPedidos) ([]entity.Pedido, error) {
+				recebido = filtro
+				return pedidos[:filtro.Limite], nil
+			},
+		}
+
+		usecase := NewPedidoUseCases(mockRepo, &MockProdutoRepository{}, &gateway.ClienteGatewayFake{}, &gateway.PagamentoGatewayFake{}, &eventos.PublicadorFake{})
+		pagina, err := usecase.RecuperarPedidos(context.Background(), entity.FiltroPedidos{Limite: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if recebido.Limite != 3 || recebido.Ordenacao != entity.OrdenarPorData {
+			t.Errorf("expected repository query with limit 3 ordered by data, got %+v", recebido)
+		}
+		if len(pagina.Pedidos) != 2 || pagina.ProximoCursor == "" {
+			t.Fatalf("expected 2 pedidos and a cursor, got %+v", pagina)
+		}
+
+		cursor, err := entity.DecodificarCursorPedidos(pagina.ProximoCursor)
+		if err != nil || cursor.Id != 2 || !cursor.CriadoEm.Equal(criado) {
+			t.Errorf("expected cursor at pedido 2, got %+v (%v)", cursor, err)
+		}
+	})
+
+	t.Run("Last page without cursor", func(t *testing.T) {
+		mockRepo := &MockPedidoRepository{
+			RecuperarPedidosMock: func(filtro entity.FiltroPedidos) ([]entity.Pedido, error) {
+				return nil, nil
+			},
+		}
+
+		usecase := NewPedidoUseCases(mockRepo, &MockProdutoRepository{}, &gateway.ClienteGatewayFake{}, &gateway.PagamentoGatewayFake{}, &eventos.PublicadorFake{})
+		pagina, err := usecase.RecuperarPedidos(context.Background(), entity.FiltroPedidos{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pagina.Pedidos == nil || len(pagina.Pedidos) != 0 || pagina.ProximoCursor != "" {
+			t.Errorf("expected empty page without cursor, got %+v", pagina)
+		}
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		cursor := entity.CursorPedidos{Ordenacao: entity.OrdenarPorId, Id: 1}
+		filtro := entity.FiltroPedidos{Status: "pronto ", Limite: 500, Ordenacao: entity.OrdenarPorDataDesc, Cursor: &cursor}
+
+		usecase := NewPedidoUseCases(&MockPedidoRepository{}, &MockProdutoRepository{}, &gateway.ClienteGatewayFake{}, &gateway.PagamentoGatewayFake{}, &eventos.PublicadorFake{})
+		_, err := usecase.RecuperarPedidos(context.Background(), filtro)
+
+		var erro *entity.ErroDominio
+		if !errors.As(err, &erro) || !errors.Is(err, entity.ErrFiltroInvalido) {
+			t.Fatalf("expected ErrFiltroInvalido, got %v", err)
+		}
+		if len(erro.Campos) != 3 {
+			t.Errorf("expected status, limite and cursor errors, got %+v", erro.Campos)
+		}
+	})
+}
+
+func TestAtualizarStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		atual       entity.StatusPedido
+		novo        entity.StatusPedido
+		expectedErr error
+	}{
+		{name: "Recebido to Em preparação", atual: entity.StatusRecebido, novo: entity.StatusEmPreparacao},
+		{name: "Em preparação to Pronto", atual: entity.StatusEmPreparacao, novo: entity.StatusPronto},
+		{name: "Pronto to Finalizado", atual: entity.StatusPronto, novo: entity.StatusFinalizado},
+		{name: "Recebido to Cancelado", atual: entity.StatusRecebido, novo: entity.StatusCancelado, expectedErr: entity.ErrCancelamentoViaStatus},
+		{name: "Recebido to Finalizado", atual: entity.StatusRecebido, novo: entity.StatusFinalizado, expectedErr: entity.ErrTransicaoStatusInvalida},
+		{name: "Pronto to Cancelado", atual: entity.StatusPronto, novo: entity.StatusCancelado, expectedErr: entity.ErrCancelamentoViaStatus},
+		{name: "Finalizado to Recebido", atual: entity.StatusFinalizado, novo: entity.StatusRecebido, expectedErr: entity.ErrTransicaoStatusInvalida},
+		{name: "Unknown status", atual: entity.StatusRecebido, novo: "pronto ", expectedErr: entity.ErrStatusInvalido},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			atualizado := false
+			var responsavel string
+			mockRepo := &MockPedidoRepository{
+				RecuperarStatusMock: func(id int) (entity.StatusPedido, error) {
+					return test.atual, nil
+				},
+				AtualizarStatusMock: func(id int, statusAtual entity.StatusPedido, status entity.StatusPedido, r string) error {
+					if statusAtual != test.atual {
+						t.Errorf("expected status %q to guard the update, got %q", test.atual, statusAtual)
+					}
+					atualizado = true
+					responsavel = r
+					return nil
+				},
+				RecuperarFilaMock:      filaVazia,
+				AtualizarPrevisoesMock: ignorarPrevisoes,
+			}
+
+			usecase := NewPedidoUseCases(mockRepo, &MockProdutoRepository{}, &gateway.ClienteGatewayFake{}, &gateway.PagamentoGatewayFake{}, &eventos.PublicadorFake{})
+			err := usecase.AtualizarStatus(context.Background(), 1, test.novo, " cozinha ")
+
+			if !errors.Is(err, test.expectedErr) {
+				t.Errorf("expected error %v, got %v", test.expectedErr, err)
+			}
+			if atualizado != (test.expectedErr == nil) {
+				t.Errorf("expected repository update to be %v, got %v", test.expectedErr == nil, atualizado)
+			}
+			if atualizado && responsavel != "cozinha" {
+				t.Errorf("expected trimmed responsavel %q, got %q", "cozinha", responsavel)
+			}
+		})
+	}
+
+	t.Run("Ready pedido and queue estimates written together", func(t *testing.T) {
+		var gravacoes [][]entity.Pedido
+		mockRepo := &MockPedidoRepository{
+			RecuperarStatusMock: func(id int) (entity.StatusPedido, error) {
+				return entity.StatusEmPreparacao, nil
+			},
+			AtualizarStatusMock: func(id int, statusAtual entity.StatusPedido, status entity.StatusPedido, r string) error {
+				return nil
+			},
+			RecuperarFilaMock: func() ([]entity.Pedido, error) {
+				return []entity.Pedido{{Id: 2, Status: entity.StatusRecebido, TempoDePreparo: 10}}, nil
+			},
+			AtualizarPrevisoesMock: func(fila []entity.Pedido) error {
+				gravacoes = append(gravacoes, fila)
+				return nil
+			},
+		}
+
+		usecase := NewPedidoUseCases(mockRepo, &MockProdutoRepository{}, &gateway.ClienteGatewayFake{}, &gateway.PagamentoGatewayFake{}, &eventos.PublicadorFake{})
+		if err := usecase.AtualizarStatus(context.Background(), 1, entity.StatusPronto, "cozinha"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(gravacoes) != 1 {
+			t.Fatalf("expected a single write, got %d", len(gravacoes))
+		}
+		fila := gravacoes[0]
+		if len(fila) != 2 || fila[0].Id != 1 || fila[1].Id != 2 || fila[0].PrevisaoPronto == nil || fila[1].PrevisaoPronto == nil {
+			t.Errorf("expected estimates for pedidos 1 and 2, got %+v", fila)
+		}
+	})
+
+	t.Run("Responsavel too long", func(t *testing.T) {
+		usecase := NewPedidoUseCases(&MockPedidoRepository{}, &MockProdutoRepository{}, &gateway.ClienteGatewayFake{}, &gateway.PagamentoGatewayFake{}, &eventos.PublicadorFake{})
+		err := usecase.AtualizarStatus(context.Background(), 1, entity.StatusPronto, strings.Repeat("a", entity.TamanhoMaximoResponsavel+1))
+		if !errors.Is(err, entity.ErrPedidoInvalido) {
+			t.Errorf("expected ErrPedidoInvalido, got %v", err)
+		}
+	})
+
+	t.Run("Status changed by another request", func(t *testing.T) {
+		mockRepo := &MockPedidoRepository{
+			RecuperarStatusMock: func(id int) (entity.StatusPedido, error) {
+				return entity.StatusPronto, nil
+			},
+			AtualizarStatusMock: func(id int, statusAtual entity.StatusPedido, status entity.StatusPedido, r string) error {
+				return fmt.Errorf("%w: status do pedido foi alterado", entity.ErrTransicaoStatusInvalida)
+			},
+		}
+
+		publicador := &eventos.PublicadorFake{}
+		usecase := NewPedidoUseCases(mockRepo, &MockProdutoRepository{}, &gateway.ClienteGatewayFake{}, &gateway.PagamentoGatewayFake{}, publicador)
+		err := usecase.AtualizarStatus(context.Background(), 1, entity.StatusFinalizado, "")
+		if !errors.Is(err, entity.ErrTransicaoStatusInvalida) {
+			t.Errorf("expected ErrTransicaoStatusInvalida, got %v", err)
+		}
+		if len(publicador.Alteracoes()) != 0 {
+			t.Errorf("expected nothing to be published, got %+v", publicador.Alteracoes())
+		}
+	})
+
+	t.Run("Unknown pedido", func(t *testing.T) {
+		mockRepo := &MockPedidoRepository{
+			RecuperarStatusMock: func(id int) (entity.StatusPedido, error) {
+				return "", entity.ErrPedidoNaoEncontrado
+			},
+		}
+
+		usecase := NewPedidoUseCases(mockRepo, &MockProdutoRepository{}, &gateway.ClienteGatewayFake{}, &gateway.PagamentoGatewayFake{}, &eventos.PublicadorFake{})
+		err := usecase.AtualizarStatus(context.Background(), 99, entity.StatusPronto, "")
+		if !errors.Is(err, entity.ErrPedidoNaoEncontrado) {
+			t.Errorf("expected ErrPedidoNaoEncontrado, got %v", err)
+		}
+	})
+}
+
+func TestConfirmarPagamento(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         entity.StatusPedido
+		pago           bool
+		aprovado       bool
+		expectedErr    error
+		expectedStatus entity.StatusPedido
+		expectedPago   bool
+		expectedEvents []entity.EventoPedido
+		reembolsado    bool
+		reentregue     bool
+	}{
+		{name: "Payment approved", status: entity.StatusRecebido, aprovado: true, expectedStatus: entity.StatusEmPreparacao, expectedPago: true, expectedEvents: []entity.EventoPedido{entity.EventoPagamento, entity.EventoStatus}},
+		{name: "Approval re-delivered", status: entity.StatusEmPreparacao, pago: true, aprovado: true, expectedStatus: entity.StatusEmPreparacao, expectedPago: true},
+		{name: "Approval applied by a concurrent delivery", status: entity.StatusRecebido, aprovado: true, reentregue: true, expectedStatus: entity.StatusRecebido},
+		{name: "Approval after a partial failure", status: entity.StatusRecebido, pago: true, aprovado: true, expectedStatus: entity.StatusEmPreparacao, expectedPago: true, expectedEvents: []entity.EventoPedido{entity.EventoStatus}},
+		{name: "Payment declined", status: entity.StatusRecebido, aprovado: false, expectedStatus: entity.StatusRecebido, expectedEvents: []entity.EventoPedido{entity.EventoPagamento}},
+		{name: "Decline re-delivered", status: entity.StatusRecebido, aprovado: false, reentregue: true, expectedStatus: entity.StatusRecebido},
+		{name: "Approval after a decline", status: entity.StatusRecebido, aprovado: true, expectedStatus: entity.StatusEmPreparacao, expectedPago: true, expectedEvents: []entity.EventoPedido{entity.EventoPagamento, entity.EventoStatus}},
+		{name: "Decline after approval", status: entity.StatusEmPreparacao, pago: true, aprovado: false, expectedErr: entity.ErrPagamentoJaProcessado, expectedStatus: entity.StatusEmPreparacao, expectedPago: true},
+		{name: "Approval after cancellation", status: entity.StatusCancelado, aprovado: true, expectedStatus: entity.StatusCancelado, expectedPago: true, expectedEvents: []entity.EventoPedido{entity.EventoPagamento}, reembolsado: true},
+		{name: "Approval after cancellation re-delivered", status: entity.StatusCancelado, pago: true, aprovado: true, expectedStatus: entity.StatusCancelado, expectedPago: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, pago := test.status, test.pago
+			var responsaveis []string
+			mockRepo := &MockPedidoRepository{
+				RecuperarStatusMock: func(id int) (entity.StatusPedido, error) {
+					return status, nil
+				},
+				AtualizarStatusMock: func(id int, atual entity.StatusPedido, s entity.StatusPedido, r string) error {
+					if atual != status {
+						t.Errorf("expected status %q to guard the update, got %q", status, atual)
+					}
+					status = s
+					responsaveis = append(responsaveis, r)
+					return nil
+				},
+				RecuperarPagamentoMock: func(id int) (bool, error) {
+					return pago, nil
+				},
+				AtualizarPagamentoMock: func(id int, p bool, r string) (bool, error) {
+					if test.reentregue {
+						return false, nil
+					}
+					pago = p
+					responsaveis = append(responsaveis, r)
+					return true, nil
+				},
+				RecuperarPedidoMock: func(id int) (entity.Pedido, error) {
+					return entity.Pedido{Id: id, Status: status, PagamentoAprovado: pago, Total: entity.Centavos(2500)}, nil
+				},
+				AprovarCanceladoMock: func(id int, valor entity.Dinheiro, r string) (entity.SolicitacaoReembolso, error) {
+					pago = true
+					responsaveis = append(responsaveis, r)
+					return entity.SolicitacaoReembolso{PedidoId: id, Valor: valor, Motivo: "cliente desistiu"}, nil
+				},
+				AtualizarReembolsoMock: func(id int, s entity.StatusReembolso) error {
+					return nil
+				},
+				RecuperarFilaMock:      filaVazia,
+				AtualizarPrevisoesMock: ignorarPrevisoes,
+			}
+
+			publicador := &eventos.PublicadorFake{}
+			pagamentos := &gateway.PagamentoGatewayFake{}
+			usecase := NewPedidoUseCases(mockRepo, &MockProdutoRepository{}, &gateway.ClienteGatewayFake{}, pagamentos, publicador)
+			err := usecase.ConfirmarPagamento(context.Background(), 1, test.aprovado)
+
+			if !errors.Is(err, test.expectedErr) {
+				t.Errorf("expected error %v, got %v", test.expectedErr, err)
+			}
+			if status != test.expectedStatus {
+				t.Errorf("expected status %q, got %q", test.expectedStatus, status)
+			}
+			if pago != test.expectedPago {
+				t.Errorf("expected pagamento_aprovado %v, got %v", test.expectedPago, pago)
+			}
+			for _, r := range responsaveis {
+				if r != entity.ResponsavelPagamento {
+					t.Errorf("expected changes recorded as %q, got %q", entity.ResponsavelPagamento, r)
+				}
+			}
+			var publicados []entity.EventoPedido
+			for _, a := range publicador.Alteracoes() {
+				publicados = append(publicados, a.Evento)
+			}
+			if !reflect.DeepEqual(publicados, test.expectedEvents) {
+				t.Errorf("expected published events %v, got %v", test.expectedEvents, publicados)
+			}
+			var esperados []entity.SolicitacaoReembolso
+			if test.reembolsado {
+				esperados = []entity.SolicitacaoReembolso{{PedidoId: 1, Valor: entity.Centavos(2500), Motivo: "cliente desistiu"}}
+			}
+			if reembolsos := pagamentos.Reembolsos(); len(reembolsos) != len(esperados) || (len(esperados) > 0 && !reflect.DeepEqual(reembolsos, esperados)) {
+				t.Errorf("expected reembolsos %+v, got %+v", esperados, reembolsos)
+			}
+		})
+	}
+}
+
+func TestEstimarPrevisoes(t *testing.T) {
+	agora := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	emCincoMinutos := agora.Add(5 * time.Minute)
+	fila := []entity.Pedido{
+		{Id: 1, Status: entity.StatusEmPreparacao, TempoDePreparo: 10, PrevisaoPronto: &emCincoMinutos},
+		{Id: 2, Status: entity.StatusRecebido, TempoDePreparo: 15},
+		{Id: 3, Status: entity.StatusRecebido, TempoDePreparo: 5},
+	}
+
+	estimarPrevisoes(fila, agora)
+
+	esperado := []time.Duration{5 * time.Minute, 20 * time.Minute, 25 * time.Minute}
+	for i, p := range fila {
+		if !p.PrevisaoPronto.Equal(agora.Add(esperado[i])) {
+			t.Errorf("pedido %d: expected previsao %v, got %v", p.Id, agora.Add(esperado[i]), *p.PrevisaoPronto)
+		}
+	}
+}
+
+func TestCancelarPedido(t *testing.T) {
+	pedidos := map[int]entity.Pedido{
+		1: {Id: 1, Status: entity.StatusRecebido, Total: entity.Centavos(2500)},
+		2: {Id: 2, Status: entity.StatusEmPreparacao, PagamentoAprovado: true, Total: entity.Centavos(2500)},
+		3: {Id: 3, Status: entity.StatusPronto, PagamentoAprovado: true, Total: entity.Centavos(2500)},
+		4: {Id: 4, Status: entity.StatusCancelado},
+	}
+
+	novoCenario := func(pagamentos *gateway.PagamentoGatewayFake) (*pedidoUseCases, *[]entity.Cancelamento, *[]entity.StatusReembolso) {
+		var cancelados []entity.Cancelamento
+		var reembolsos []entity.StatusReembolso
+		mockRepo := &MockPedidoRepository{
+			RecuperarPedidoMock: func(id int) (entity.Pedido, error) {
+				if p, ok := pedidos[id]; ok {
+					return p, nil
+				}
+				return entity.Pedido{}, entity.ErrPedidoNaoEncontrado
+			},
+			CancelarPedidoMock: func(c entity.Cancelamento, statusAtual entity.StatusPedido) error {
+				if statusAtual != pedidos[c.PedidoId].Status {
+					t.Errorf("expected status %q to guard the update, got %q", pedidos[c.PedidoId].Status, statusAtual)
+				}
+				if c.Reembolso != nil {
+					gravado := *c.Reembolso
+					c.Reembolso = &gravado
+				}
+				cancelados = append(cancelados, c)
+				return nil
+			},
+			AtualizarReembolsoMock: func(id int, status entity.StatusReembolso) error {
+				reembolsos = append(reembolsos, status)
+				return nil
+			},
+			RecuperarFilaMock:      filaVazia,
+			AtualizarPrevisoesMock: ignorarPrevisoes,
+		}
+		return NewPedidoUseCases(mockRepo, &MockProdutoRepository{}, &gateway.ClienteGatewayFake{}, pagamentos, &eventos.PublicadorFake{}), &cancelados, &reembolsos
+	}
+
+	t.Run("Unpaid pedido is cancelled without refund", func(t *testing.T) {
+		pagamentos := &gateway.PagamentoGatewayFake{}
+		usecase, cancelados, _ := novoCenario(pagamentos)
+
+		c, err := usecase.CancelarPedido(context.Background(), 1, "  cliente desistiu ", "atendente-3")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.Motivo != "cliente desistiu" || c.CanceladoPor != "atendente-3" || c.CanceladoEm.IsZero() || c.Reembolso != nil {
+			t.Errorf("unexpected cancelamento: %+v", c)
+		}
+		if len(*cancelados) != 1 || len(pagamentos.Reembolsos()) != 0 {
+			t.Errorf("expected 1 cancelamento and no reembolso, got %d and %d", len(*cancelados), len(pagamentos.Reembolsos()))
+		}
+	})
+
+	t.Run("Paid pedido requests a refund of the total", func(t *testing.T) {
+		pagamentos := &gateway.PagamentoGatewayFake{}
+		usecase, cancelados, reembolsos := novoCenario(pagamentos)
+
+		c, err := usecase.CancelarPedido(context.Background(), 2, "item em falta", "cozinha")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.Reembolso == nil || c.Reembolso.Valor.Centavos != 2500 || c.Reembolso.Status != entity.ReembolsoSolicitado {
+			t.Errorf("expected a requested reembolso of 25.00, got %+v", c.Reembolso)
+		}
+		if r := (*cancelados)[0].Reembolso; r == nil || r.Status != entity.ReembolsoPendente {
+			t.Errorf("expected the reembolso to be stored as pending, got %+v", r)
+		}
+		expected := []entity.SolicitacaoReembolso{{PedidoId: 2, Valor: entity.Centavos(2500), Motivo: "item em falta"}}
+		if !reflect.DeepEqual(pagamentos.Reembolsos(), expected) {
+			t.Errorf("expected %+v, got %+v", expected, pagamentos.Reembolsos())
+		}
+		if !reflect.DeepEqual(*reembolsos, []entity.StatusReembolso{entity.ReembolsoSolicitado}) {
+			t.Errorf("expected the reembolso to be marked as requested, got %v", *reembolsos)
+		}
+		publicadas := usecase.eventos.(*eventos.PublicadorFake).Alteracoes()
+		if len(publicadas) != 1 || publicadas[0].Evento != entity.EventoCancelamento || publicadas[0].Status != entity.StatusCancelado || !publicadas[0].PagamentoAprovado || publicadas[0].Responsavel != "cozinha" {
+			t.Errorf("expected the cancellation to be published, got %+v", publicadas)
+		}
+	})
+
+	t.Run("Refund failure keeps the cancellation", func(t *testing.T) {
+		pagamentos := &gateway.PagamentoGatewayFake{Err: entity.ErrPagamentoIndisponivel}
+		usecase, cancelados, reembolsos := novoCenario(pagamentos)
+
+		c, err := usecase.CancelarPedido(context.Background(), 2, "item em falta", "cozinha")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.Reembolso == nil || c.Reembolso.Status != entity.ReembolsoPendente {
+			t.Errorf("expected the reembolso to stay pending, got %+v", c.Reembolso)
+		}
+		if len(*cancelados) != 1 || len(*reembolsos) != 0 {
+			t.Errorf("expected the cancelamento to be kept, got %d cancelamentos and %v", len(*cancelados), *reembolsos)
+		}
+	})
+
+	tests := []struct {
+		name         string
+		id           int
+		motivo       string
+		canceladoPor string
+		expectedErr  error
+	}{
+		{name: "Pedido already Pronto", id: 3, motivo: "demorou", canceladoPor: "cliente", expectedErr: entity.ErrCancelamentoNaoPermitido},
+		{name: "Pedido already cancelled", id: 4, motivo: "demorou", canceladoPor: "cliente", expectedErr: entity.ErrCancelamentoNaoPermitido},
+		{name: "Unknown pedido", id: 99, motivo: "demorou", canceladoPor: "cliente", expectedErr: entity.ErrPedidoNaoEncontrado},
+		{name: "Missing motivo", id: 1, motivo: "   ", canceladoPor: "cliente", expectedErr: entity.ErrCancelamentoInvalido},
+		{name: "Missing responsavel", id: 1, motivo: "demorou", expectedErr: entity.ErrCancelamentoInvalido},
+		{name: "Motivo too long", id: 1, motivo: strings.Repeat("a", entity.TamanhoMaximoMotivoCancelamento+1), canceladoPor: "cliente", expectedErr: entity.ErrCancelamentoInvalido},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pagamentos := &gateway.PagamentoGatewayFake{}
+			usecase, cancelados, _ := novoCenario(pagamentos)
+
+			_, err := usecase.CancelarPedido(context.Background(), tt.id, tt.motivo, tt.canceladoPor)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected %v, got %v", tt.expectedErr, err)
+			}
+			if len(*cancelados) != 0 || len(pagamentos.Reembolsos()) != 0 {
+				t.Errorf("expected nothing to be cancelled or refunded")
+			}
+		})
+	}
+}
+
+func TestReenviarReembolsos(t *testing.T) {
+	pendentes := []entity.SolicitacaoReembolso{
+		{PedidoId: 1, Valor: entity.Centavos(2500), Motivo: "item em falta"},
+		{PedidoId: 2, Valor: entity.Centavos(1800), Motivo: "cliente desistiu"},
+	}
+	novoCenario := func(pagamentos *gateway.PagamentoGatewayFake, errRepo error) (*pedidoUseCases, *[]int) {
+		var solicitados []int
+		mockRepo := &MockPedidoRepository{
+			ReembolsosPendentesMock: func() ([]entity.SolicitacaoReembolso, error) {
+				return pendentes, errRepo
+			},
+			AtualizarReembolsoMock: func(id int, status entity.StatusReembolso) error {
+				if status != entity.ReembolsoSolicitado {
+					t.Errorf("expected status %q, got %q", entity.ReembolsoSolicitado, status)
+				}
+				solicitados = append(solicitados, id)
+				return nil
+			},
+		}
+		return NewPedidoUseCases(mockRepo, &MockProdutoRepository{}, &gateway.ClienteGatewayFake{}, pagamentos, &eventos.PublicadorFake{}), &solicitados
+	}
+
+	t.Run("Pending refunds are resent", func(t *testing.T) {
+		pagamentos := &gateway.PagamentoGatewayFake{}
+		usecase, solicitados := novoCenario(pagamentos, nil)
+
+		n, err := usecase.ReenviarReembolsos(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n != 2 || !reflect.DeepEqual(pagamentos.Reembolsos(), pendentes) {
+			t.Errorf("expected both reembolsos to be requested, got %d and %+v", n, pagamentos.Reembolsos())
+		}
+		if !reflect.DeepEqual(*solicitados, []int{1, 2}) {
+			t.Errorf("expected both reembolsos to be marked as requested, got %v", *solicitados)
+		}
+	})
+
+	t.Run("Payment service failure", func(t *testing.T) {
+		usecase, solicitados := novoCenario(&gateway.PagamentoGatewayFake{Err: entity.ErrPagamentoIndisponivel}, nil)
+
+		n, err := usecase.ReenviarReembolsos(context.Background())
+		if !errors.Is(err, entity.ErrPagamentoIndisponivel) {
+			t.Errorf("expected ErrPagamentoIndisponivel, got %v", err)
+		}
+		if n != 0 || len(*solicitados) != 0 {
+			t.Errorf("expected the reembolsos to stay pending, got %d and %v", n, *solicitados)
+		}
+	})
+
+	t.Run("Failure retrieving pending refunds", func(t *testing.T) {
+		pagamentos := &gateway.PagamentoGatewayFake{}
+		usecase, _ := novoCenario(pagamentos, errors.New("db down"))
+
+		if _, err := usecase.ReenviarReembolsos(context.Background()); err == nil {
+			t.Errorf("expected the repository error")
+		}
+		if len(pagamentos.Reembolsos()) != 0 {
+			t.Errorf("expected nothing to be requested, got %+v", pagamentos.Reembolsos())
+		}
+	})
+}
@@ -1,46 +1,93 @@
 package produto_usecase
 
 import (
+	"context"
 	"errors"
+	"fmt"
 
 	"github.com/gomesmatheus/tc-pedido/domain/entity"
 	"github.com/gomesmatheus/tc-pedido/infraestructure/persistence"
 )
 
 type produtoUseCases struct {
-	database persistence.ProdutoRepository
+	database   persistence.ProdutoRepository
+	categorias persistence.CategoriaRepository
 }
 
-func NewProdutoUseCases(ProdutoRepository persistence.ProdutoRepository) *produtoUseCases {
+func NewProdutoUseCases(ProdutoRepository persistence.ProdutoRepository, CategoriaRepository persistence.CategoriaRepository) *produtoUseCases {
 	return &produtoUseCases{
-		database: ProdutoRepository,
+		database:   ProdutoRepository,
+		categorias: CategoriaRepository,
 	}
 }
 
-func (usecase *produtoUseCases) CriarProduto(p entity.Produto) (entity.Produto, error) {
-	if !isProdutoValido(p) {
-		return p, errors.New("Produto inválido")
+func (usecase *produtoUseCases) CriarProduto(ctx context.Context, p entity.Produto) (entity.Produto, error) {
+	if campos := validarProduto(p); len(campos) > 0 {
+		return p, entity.ErrProdutoInvalido.ComCampos(campos...)
 	}
 
-	return usecase.database.CriarProduto(p)
+	if err := usecase.validarCategoria(ctx, p.CategoriaId); err != nil {
+		return p, err
+	}
+
+	return usecase.database.CriarProduto(ctx, p)
+}
+
+func (usecase *produtoUseCases) RecuperarProdutos(ctx context.Context, categoriaId int) ([]entity.Produto, error) {
+	return usecase.database.RecuperarProdutos(ctx, categoriaId)
 }
 
-func (usecase *produtoUseCases) RecuperarProdutos(categoriaId int) ([]entity.Produto, error) {
-	return usecase.database.RecuperarProdutos(categoriaId)
+func (usecase *produtoUseCases) RecuperarTodosProdutos(ctx context.Context) ([]entity.Produto, error) {
+	return usecase.database.RecuperarTodosProdutos(ctx)
 }
 
-func (usecase *produtoUseCases) AtualizarProduto(id int, p entity.Produto) error {
-	if !isProdutoValido(p) {
-		return errors.New("Produto inválido")
+func (usecase *produtoUseCases) RecuperarProduto(ctx context.Context, id int) (entity.Produto, error) {
+	return usecase.database.RecuperarProduto(ctx, id)
+}
+
+func (usecase *produtoUseCases) AtualizarProduto(ctx context.Context, id int, p entity.Produto) error {
+	if campos := validarProduto(p); len(campos) > 0 {
+		return entity.ErrProdutoInvalido.ComCampos(campos...)
+	}
+
+	if err := usecase.validarCategoria(ctx, p.CategoriaId); err != nil {
+		return err
 	}
 
-	return usecase.database.AtualizarProduto(id, p)
+	return usecase.database.AtualizarProduto(ctx, id, p)
 }
 
-func (usecase *produtoUseCases) DeletarProduto(id int) error {
-	return usecase.database.DeletarProduto(id)
+func (usecase *produtoUseCases) DeletarProduto(ctx context.Context, id int) error {
+	return usecase.database.DeletarProduto(ctx, id)
 }
 
-func isProdutoValido(p entity.Produto) bool {
-	return p.Nome != "" && p.Preco != 0 && p.Descricao != "" && p.CategoriaId != 0 && p.TempoDePreparo != 0
+func (usecase *produtoUseCases) validarCategoria(ctx context.Context, categoriaId int) error {
+	_, err := usecase.categorias.RecuperarCategoria(ctx, categoriaId)
+	if errors.Is(err, entity.ErrCategoriaNaoEncontrada) {
+		campo := entity.ErroCampo{Campo: "categoria_id", Mensagem: fmt.Sprintf("categoria %d não encontrada", categoriaId)}
+		return fmt.Errorf("%w: %w", entity.ErrProdutoInvalido.ComCampos(campo), err)
+	}
+	return err
+}
+
+func validarProduto(p entity.Produto) []entity.ErroCampo {
+	var campos []entity.ErroCampo
+	if p.Nome == "" {
+		campos = append(campos, entity.ErroCampo{Campo: "nome", Mensagem: "obrigatório"})
+	}
+	if p.Descricao == "" {
+		campos = append(campos, entity.ErroCampo{Campo: "descricao", Mensagem: "obrigatório"})
+	}
+	if p.Preco.Zero() {
+		campos = append(campos, entity.ErroCampo{Campo: "preco", Mensagem: "obrigatório"})
+	} else if !p.Preco.Positivo() {
+		campos = append(campos, entity.ErroCampo{Campo: "preco", Mensagem: "deve ser positivo"})
+	}
+	if p.CategoriaId == 0 {
+		campos = append(campos, entity.ErroCampo{Campo: "categoria_id", Mensagem: "obrigatório"})
+	}
+	if p.TempoDePreparo == 0 {
+		campos = append(campos, entity.ErroCampo{Campo: "tempo_de_preparo", Mensagem: "obrigatório"})
+	}
+	return campos
 }
@@ -1,6 +1,7 @@
 package produto_usecase
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -10,26 +11,64 @@ import (
 type MockProdutoRepository struct {
 	CriarProdutoMock      func(p entity.Produto) (entity.Produto, error)
 	RecuperarProdutosMock func(categoriaId int) ([]entity.Produto, error)
+	RecuperarTodosMock    func() ([]entity.Produto, error)
+	RecuperarProdutoMock  func(id int) (entity.Produto, error)
 	AtualizarProdutoMock  func(id int, p entity.Produto) error
 	DeletarProdutoMock    func(id int) error
 }
 
-func (m *MockProdutoRepository) CriarProduto(p entity.Produto) (entity.Produto, error) {
+func (m *MockProdutoRepository) CriarProduto(ctx context.Context, p entity.Produto) (entity.Produto, error) {
 	return m.CriarProdutoMock(p)
 }
 
-func (m *MockProdutoRepository) RecuperarProdutos(categoriaId int) ([]entity.Produto, error) {
+func (m *MockProdutoRepository) RecuperarProdutos(ctx context.Context, categoriaId int) ([]entity.Produto, error) {
 	return m.RecuperarProdutosMock(categoriaId)
 }
 
-func (m *MockProdutoRepository) AtualizarProduto(id int, p entity.Produto) error {
+func (m *MockProdutoRepository) RecuperarTodosProdutos(ctx context.Context) ([]entity.Produto, error) {
+	return m.RecuperarTodosMock()
+}
+
+func (m *MockProdutoRepository) RecuperarProduto(ctx context.Context, id int) (entity.Produto, error) {
+	return m.RecuperarProdutoMock(id)
+}
+
+func (m *MockProdutoRepository) AtualizarProduto(ctx context.Context, id int, p entity.Produto) error {
 	return m.AtualizarProdutoMock(id, p)
 }
 
-func (m *MockProdutoRepository) DeletarProduto(id int) error {
+func (m *MockProdutoRepository) DeletarProduto(ctx context.Context, id int) error {
 	return m.DeletarProdutoMock(id)
 }
 
+type MockCategoriaRepository struct {
+	Categorias map[int]entity.Categoria
+}
+
+func (m *MockCategoriaRepository) CriarCategoria(ctx context.Context, c entity.Categoria) (entity.Categoria, error) {
+	return c, nil
+}
+
+func (m *MockCategoriaRepository) RecuperarCategorias(ctx context.Context) ([]entity.Categoria, error) {
+	return nil, nil
+}
+
+func (m *MockCategoriaRepository) RecuperarCategoria(ctx context.Context, id int) (entity.Categoria, error) {
+	c, ok := m.Categorias[id]
+	if !ok {
+		return c, entity.ErrCategoriaNaoEncontrada
+	}
+	return c, nil
+}
+
+func (m *MockCategoriaRepository) AtualizarCategoria(ctx context.Context, id int, c entity.Categoria) error {
+	return nil
+}
+
+func (m *MockCategoriaRepository) DeletarCategoria(ctx context.Context, id int) error {
+	return nil
+}
+
 func TestProdutoUseCases(t *testing.T) {
 	mockRepo := &MockProdutoRepository{
 		CriarProdutoMock: func(p entity.Produto) (entity.Produto, error) {
@@ -61,11 +100,15 @@ func TestProdutoUseCases(t *testing.T) {
 		},
 	}
 
-	usecase := NewProdutoUseCases(mockRepo)
+	mockCategoriaRepo := &MockCategoriaRepository{
+		Categorias: map[int]entity.Categoria{1: {Id: 1, Descricao: "Lanche"}},
+	}
+
+	usecase := NewProdutoUseCases(mockRepo, mockCategoriaRepo)
 
 	t.Run("CriarProduto - Valid Product", func(t *testing.T) {
-		produto := entity.Produto{Nome: "Produto1", CategoriaId: 1, Preco: 10.0, Descricao: "Desc", TempoDePreparo: 15}
-		_, err := usecase.CriarProduto(produto)
+		produto := entity.Produto{Nome: "Produto1", CategoriaId: 1, Preco: entity.Centavos(1000), Descricao: "Desc", TempoDePreparo: 15}
+		_, err := usecase.CriarProduto(context.Background(), produto)
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
 		}
@@ -73,29 +116,37 @@ func TestProdutoUseCases(t *testing.T) {
 
 	t.Run("CriarProduto - Invalid Product", func(t *testing.T) {
 		produto := entity.Produto{Nome: "Invalid", CategoriaId: 0}
-		_, err := usecase.CriarProduto(produto)
+		_, err := usecase.CriarProduto(context.Background(), produto)
 		if err == nil || err.Error() != "Produto inválido" {
 			t.Errorf("expected 'Produto inválido', got %v", err)
 		}
 	})
 
+	t.Run("CriarProduto - Unknown Category", func(t *testing.T) {
+		produto := entity.Produto{Nome: "Produto1", CategoriaId: 9, Preco: entity.Centavos(1000), Descricao: "Desc", TempoDePreparo: 15}
+		_, err := usecase.CriarProduto(context.Background(), produto)
+		if !errors.Is(err, entity.ErrCategoriaNaoEncontrada) {
+			t.Errorf("expected ErrCategoriaNaoEncontrada, got %v", err)
+		}
+	})
+
 	t.Run("RecuperarProdutos - Valid Category", func(t *testing.T) {
-		_, err := usecase.RecuperarProdutos(1)
+		_, err := usecase.RecuperarProdutos(context.Background(), 1)
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
 		}
 	})
 
 	t.Run("RecuperarProdutos - Invalid Category", func(t *testing.T) {
-		_, err := usecase.RecuperarProdutos(0)
+		_, err := usecase.RecuperarProdutos(context.Background(), 0)
 		if err == nil || err.Error() != "Categoria inválida" {
 			t.Errorf("expected 'Categoria inválida', got %v", err)
 		}
 	})
 
 	t.Run("AtualizarProduto - Valid Product", func(t *testing.T) {
-		produto := entity.Produto{Nome: "Produto1", CategoriaId: 1, Preco: 10.0, Descricao: "Desc", TempoDePreparo: 15}
-		err := usecase.AtualizarProduto(1, produto)
+		produto := entity.Produto{Nome: "Produto1", CategoriaId: 1, Preco: entity.Centavos(1000), Descricao: "Desc", TempoDePreparo: 15}
+		err := usecase.AtualizarProduto(context.Background(), 1, produto)
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
 		}
@@ -103,21 +154,21 @@ func TestProdutoUseCases(t *testing.T) {
 
 	t.Run("AtualizarProduto - Invalid Product", func(t *testing.T) {
 		produto := entity.Produto{Nome: "Invalid", CategoriaId: 0}
-		err := usecase.AtualizarProduto(0, produto)
+		err := usecase.AtualizarProduto(context.Background(), 0, produto)
 		if err == nil || err.Error() != "Produto inválido" {
 			t.Errorf("expected 'Produto inválido', got %v", err)
 		}
 	})
 
 	t.Run("DeletarProduto - Valid ID", func(t *testing.T) {
-		err := usecase.DeletarProduto(1)
+		err := usecase.DeletarProduto(context.Background(), 1)
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
 		}
 	})
 
 	t.Run("DeletarProduto - Invalid ID", func(t *testing.T) {
-		err := usecase.DeletarProduto(0)
+		err := usecase.DeletarProduto(context.Background(), 0)
 		if err == nil || err.Error() != "ID inválido" {
 			t.Errorf("expected 'ID inválido', got %v", err)
 		}